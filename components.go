@@ -0,0 +1,117 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// namedResponse pairs a reusable response with its component name.
+type namedResponse struct {
+	Name     string
+	Response *openapi3.Response
+}
+
+// namedParameter pairs a reusable parameter with its component name.
+type namedParameter struct {
+	Name      string
+	Parameter *openapi3.Parameter
+}
+
+// namedSchema pairs a component schema with its name.
+type namedSchema struct {
+	Name   string
+	Schema *openapi3.Schema
+}
+
+// componentResponse returns the reusable response declared under the given
+// name, or nil.
+func componentResponse(swagger *openapi3.T, name string) *openapi3.Response {
+	if swagger == nil || swagger.Components == nil {
+		return nil
+	}
+	if ref := swagger.Components.Responses[name]; ref != nil {
+		return ref.Value
+	}
+	return nil
+}
+
+// componentParameter returns the reusable parameter declared under the given
+// name, or nil.
+func componentParameter(swagger *openapi3.T, name string) *openapi3.Parameter {
+	if swagger == nil || swagger.Components == nil {
+		return nil
+	}
+	if ref := swagger.Components.Parameters[name]; ref != nil {
+		return ref.Value
+	}
+	return nil
+}
+
+// sortedComponentResponses lists the reusable responses sorted by name.
+func sortedComponentResponses(swagger *openapi3.T) []namedResponse {
+	responses := []namedResponse{}
+	if swagger == nil || swagger.Components == nil {
+		return responses
+	}
+	for name, ref := range swagger.Components.Responses {
+		if ref != nil && ref.Value != nil {
+			responses = append(responses, namedResponse{Name: name, Response: ref.Value})
+		}
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Name < responses[j].Name })
+	return responses
+}
+
+// sortedComponentParameters lists the reusable parameters sorted by name.
+func sortedComponentParameters(swagger *openapi3.T) []namedParameter {
+	params := []namedParameter{}
+	if swagger == nil || swagger.Components == nil {
+		return params
+	}
+	for name, ref := range swagger.Components.Parameters {
+		if ref != nil && ref.Value != nil {
+			params = append(params, namedParameter{Name: name, Parameter: ref.Value})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// allSchemaNames lists the names of the component schemas, sorted.
+func allSchemaNames(swagger *openapi3.T) []string {
+	schemas := componentSchemas(swagger)
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedSchemas lists the component schemas sorted by name. A registry of
+// generated types can be built in one template by ranging over it, e.g.
+// {{range sortedSchemas}}"{{.Name}}": reflect.TypeOf({{goName .Name}}{}),{{end}}.
+func sortedSchemas(swagger *openapi3.T) []namedSchema {
+	schemas := []namedSchema{}
+	for _, name := range allSchemaNames(swagger) {
+		if ref := swagger.Components.Schemas[name]; ref != nil && ref.Value != nil {
+			schemas = append(schemas, namedSchema{Name: name, Schema: ref.Value})
+		}
+	}
+	return schemas
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponentResponse(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "responses", "version": "1"},
+		"paths": {"/pets": {"get": {"responses": {
+			"200": {"description": "ok"},
+			"404": {"$ref": "#/components/responses/NotFound"}
+		}}}},
+		"components": {
+			"responses": {"NotFound": {"description": "not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}},
+			"schemas": {"Error": {"type": "object"}}
+		}
+	}`, false)
+	resp := componentResponse(swagger, "NotFound")
+	if resp == nil || resp.Description == nil || *resp.Description != "not found" {
+		t.Fatalf("componentResponse(NotFound) = %+v, want the declared response", resp)
+	}
+	if schema := anyContentSchema(resp); schema == nil || schema.Ref != "#/components/schemas/Error" {
+		t.Errorf("NotFound schema = %+v, want a reference to Error", schema)
+	}
+	used := testOperation(t, swagger, "GET", "/pets").Responses["404"]
+	if used.Ref != "#/components/responses/NotFound" || used.Value != resp {
+		t.Errorf("404 of GET /pets does not resolve to NotFound")
+	}
+	if got := componentResponse(swagger, "Missing"); got != nil {
+		t.Errorf("componentResponse(Missing) = %+v, want nil", got)
+	}
+	if got := sortedComponentResponses(swagger); len(got) != 1 || got[0].Name != "NotFound" {
+		t.Errorf("sortedComponentResponses() = %+v, want NotFound", got)
+	}
+}
+
+func TestAllSchemaNames(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "names", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Zebra": {"type": "object"},
+			"Apple": {"type": "string"},
+			"Mango": {"type": "integer"}
+		}}
+	}`, false)
+	if got, want := allSchemaNames(swagger), []string{"Apple", "Mango", "Zebra"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allSchemaNames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// specDiff lists, in readable form, the paths, operations and component
+// schemas added (+), removed (-) or modified (~) between two specs. A path is
+// modified when its own fields, such as its parameters or servers, change;
+// changes to its operations are listed separately.
+func specDiff(oldSpec, newSpec *openapi3.T) []string {
+	var changes []string
+	oldPaths, newPaths := oldSpec.Paths, newSpec.Paths
+	for _, path := range unionKeys(pathKeys(oldPaths), pathKeys(newPaths)) {
+		oldItem, newItem := oldPaths[path], newPaths[path]
+		switch {
+		case oldItem == nil:
+			changes = append(changes, "+ path "+path)
+			continue
+		case newItem == nil:
+			changes = append(changes, "- path "+path)
+			continue
+		case !sameJSON(withoutOperations(oldItem), withoutOperations(newItem)):
+			changes = append(changes, "~ path "+path)
+		}
+		for _, method := range httpMethods {
+			oldOp, newOp := oldItem.GetOperation(method), newItem.GetOperation(method)
+			switch {
+			case oldOp == nil && newOp == nil:
+			case oldOp == nil:
+				changes = append(changes, fmt.Sprintf("+ operation %s %s", method, path))
+			case newOp == nil:
+				changes = append(changes, fmt.Sprintf("- operation %s %s", method, path))
+			case !sameJSON(oldOp, newOp):
+				changes = append(changes, fmt.Sprintf("~ operation %s %s", method, path))
+			}
+		}
+	}
+	oldSchemas, newSchemas := componentSchemas(oldSpec), componentSchemas(newSpec)
+	for _, name := range unionKeys(schemaKeys(oldSchemas), schemaKeys(newSchemas)) {
+		oldSchema, okOld := oldSchemas[name]
+		newSchema, okNew := newSchemas[name]
+		switch {
+		case !okOld:
+			changes = append(changes, "+ schema "+name)
+		case !okNew:
+			changes = append(changes, "- schema "+name)
+		case !sameJSON(oldSchema, newSchema):
+			changes = append(changes, "~ schema "+name)
+		}
+	}
+	return changes
+}
+
+// withoutOperations returns a copy of the path item without its operations.
+func withoutOperations(item *openapi3.PathItem) *openapi3.PathItem {
+	stripped := *item
+	for _, method := range httpMethods {
+		stripped.SetOperation(method, nil)
+	}
+	return &stripped
+}
+
+// sameJSON reports whether a and b encode to equivalent JSON documents.
+func sameJSON(a, b interface{}) bool {
+	var decodedA, decodedB interface{}
+	for _, v := range []struct {
+		src interface{}
+		dst *interface{}
+	}{{a, &decodedA}, {b, &decodedB}} {
+		raw, err := json.Marshal(v.src)
+		if err != nil {
+			return false
+		}
+		if err := json.Unmarshal(raw, v.dst); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(decodedA, decodedB)
+}
+
+func pathKeys(paths openapi3.Paths) []string {
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func schemaKeys(schemas openapi3.Schemas) []string {
+	keys := make([]string, 0, len(schemas))
+	for k := range schemas {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// unionKeys returns the sorted, deduplicated union of a and b.
+func unionKeys(a, b []string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, k := range append(append([]string{}, a...), b...) {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpecDiff(t *testing.T) {
+	oldSpec := parseTestSpec(t, petstoreSpec, false)
+	if got := specDiff(oldSpec, parseTestSpec(t, petstoreSpec, false)); len(got) != 0 {
+		t.Errorf("specDiff(same spec) = %v, want no changes", got)
+	}
+	newSpec := parseTestSpec(t, strings.Replace(petstoreSpec, `"paths": {`, `"paths": {
+		"/stores": {"get": {"operationId": "listStores", "responses": {"200": {"description": "ok"}}}},`, 1), false)
+	want := []string{"+ path /stores"}
+	if got := specDiff(oldSpec, newSpec); !reflect.DeepEqual(got, want) {
+		t.Errorf("specDiff() = %v, want %v", got, want)
+	}
+	if got, want := specDiff(newSpec, oldSpec), []string{"- path /stores"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("specDiff(reversed) = %v, want %v", got, want)
+	}
+	paramSpec := parseTestSpec(t, strings.Replace(petstoreSpec, `"/pets": {`, `"/pets": {
+		"parameters": [{"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string"}}],`, 1), false)
+	if got, want := specDiff(oldSpec, paramSpec), []string{"~ path /pets"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("specDiff(path-level parameter) = %v, want %v", got, want)
+	}
+}
+
+func TestDiffExitStatus(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"old.json": petstoreSpec,
+		"new.json": strings.Replace(petstoreSpec, `"paths": {`, `"paths": {
+			"/stores": {"get": {"operationId": "listStores", "responses": {"200": {"description": "ok"}}}},`, 1),
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-diff", "old.json", "old.json"); code != 0 || out != "" {
+		t.Errorf("diff of identical specs = %d %q, want 0 and no output", code, out)
+	}
+	out, code := runOpenapigen(t, dir, nil, "-diff", "old.json", "new.json")
+	if code != 1 || out != "+ path /stores\n" {
+		t.Errorf("diff with an added path = %d %q, want 1 and the added path", code, out)
+	}
+}
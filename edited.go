@@ -0,0 +1,40 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+)
+
+const (
+	// editedMarker flags a generated file as hand-edited when found anywhere
+	// in its contents, e.g. in a "// openapigen:edited" comment.
+	editedMarker = "openapigen:edited"
+	// keepSuffix names the sidecar file that flags its sibling as
+	// hand-edited, as in foo.go.openapigen-keep.
+	keepSuffix = ".openapigen-keep"
+)
+
+// isEdited reports whether the existing file fn was marked as hand-edited,
+// and thus must not be overwritten or removed by openapigen.
+func isEdited(fn string) bool {
+	if _, err := os.Stat(fn + keepSuffix); err == nil {
+		return true
+	}
+	b, err := ioutil.ReadFile(fn)
+	return err == nil && bytes.Contains(b, []byte(editedMarker))
+}
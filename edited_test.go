@@ -0,0 +1,63 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditedFilesArePreserved(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/marked.go.tpl": "package generated",
+		"tpl/kept.go.tpl":   "package generated",
+		"tpl/plain.go.tpl":  "package generated",
+	})
+	args := []string{"-spec", "spec.json", "-template", "tpl", "-output", "out", "-manifest", "manifest.json", "-prune-stale"}
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("first run failed: %s", out)
+	}
+	writeTestFiles(t, dir, map[string]string{
+		"out/marked.go":            "// openapigen:edited\npackage edited",
+		"out/kept.go":              "package edited",
+		"out/kept.go" + keepSuffix: "",
+		"out/plain.go":             "package edited",
+	})
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("second run failed: %s", out)
+	}
+	for fn, want := range map[string]string{
+		"marked.go": "// openapigen:edited\npackage edited",
+		"kept.go":   "package edited",
+		"plain.go":  "package generated",
+	} {
+		if got := readTestFile(t, filepath.Join(dir, "out", fn)); got != want {
+			t.Errorf("%s = %q, want %q", fn, got, want)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "tpl", "marked.go.tpl")); err != nil {
+		t.Fatal(err)
+	}
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("third run failed: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "marked.go")); err != nil {
+		t.Errorf("-prune-stale removed a hand-edited file: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxFailureExitCode caps the exit status reporting the number of failed
+// templates, keeping it clear of the statuses shells reserve.
+const maxFailureExitCode = 125
+
+// templateFailure records a template that failed to render under
+// -keep-going.
+type templateFailure struct {
+	name string
+	err  error
+}
+
+// failureReport renders the failures grouped by template directory, as an
+// indented tree.
+func failureReport(failures []templateFailure) string {
+	groups := make(map[string][]templateFailure)
+	var dirs []string
+	for _, f := range failures {
+		dir := path.Dir(f.name)
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], f)
+	}
+	sort.Strings(dirs)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d template(s) failed to render:", len(failures))
+	for _, dir := range dirs {
+		fmt.Fprintf(&sb, "\n  %s/", dir)
+		for _, f := range groups[dir] {
+			fmt.Fprintf(&sb, "\n    %s: %v", path.Base(f.name), f.err)
+		}
+	}
+	return sb.String()
+}
+
+// failureExitCode returns the exit status reporting n failed templates.
+func failureExitCode(n int) int {
+	if n > maxFailureExitCode {
+		return maxFailureExitCode
+	}
+	return n
+}
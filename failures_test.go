@@ -0,0 +1,45 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFailureReport(t *testing.T) {
+	failures := []templateFailure{
+		{name: "models/types.go.tpl", err: errors.New("boom")},
+		{name: "api/client.go.tpl", err: errors.New("bad pipe")},
+		{name: "models/enums.go.tpl", err: errors.New("no such function")},
+	}
+	const want = "3 template(s) failed to render:" +
+		"\n  api/" +
+		"\n    client.go.tpl: bad pipe" +
+		"\n  models/" +
+		"\n    types.go.tpl: boom" +
+		"\n    enums.go.tpl: no such function"
+	if got := failureReport(failures); got != want {
+		t.Errorf("failureReport() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFailureExitCode(t *testing.T) {
+	for n, want := range map[int]int{1: 1, 2: 2, maxFailureExitCode: maxFailureExitCode, 300: maxFailureExitCode} {
+		if got := failureExitCode(n); got != want {
+			t.Errorf("failureExitCode(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stringList is a flag.Value that accumulates every occurrence of a
+// repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// octalMode is a flag.Value holding permission bits written in octal, as in
+// chmod. Modes given explicitly are applied exactly, regardless of the umask.
+type octalMode struct {
+	perm os.FileMode
+	set  bool
+}
+
+func (m *octalMode) String() string {
+	return fmt.Sprintf("%#o", uint32(m.perm))
+}
+
+func (m *octalMode) Set(s string) error {
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > 0777 {
+		return fmt.Errorf("invalid permission bits %q: expected an octal value between 0 and 0777", s)
+	}
+	m.perm, m.set = os.FileMode(v), true
+	return nil
+}
+
+// chmod sets fn to the mode when it was given explicitly. Otherwise fn keeps
+// the mode it was created with, masked by the umask.
+func (m octalMode) chmod(fn string) error {
+	if !m.set {
+		return nil
+	}
+	return os.Chmod(fn, m.perm)
+}
+
+// isFlagSet reports whether the named flag was given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// envFlagPrefix prefixes the environment variables that provide default
+// values for flags, as in OPENAPIGEN_OUTPUT for -output.
+const envFlagPrefix = "OPENAPIGEN_"
+
+// envFlagName returns the environment variable backing the named flag.
+func envFlagName(name string) string {
+	return envFlagPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// applyEnvFlags sets every flag of fs not given on the command line from its
+// OPENAPIGEN_<NAME> environment variable, when present. -spec is skipped
+// because $OPENAPIGEN_SPEC carries the raw spec contents instead.
+func applyEnvFlags(fs *flag.FlagSet) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == "spec" {
+			return
+		}
+		v, ok := os.LookupEnv(envFlagName(f.Name))
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, v); setErr != nil {
+			err = fmt.Errorf("invalid value %q for $%s: %w", v, envFlagName(f.Name), setErr)
+		}
+	})
+	return err
+}
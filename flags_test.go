@@ -0,0 +1,70 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestApplyEnvFlags(t *testing.T) {
+	fs := flag.NewFlagSet("openapigen", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	output := fs.String("output", ".", "")
+	template := fs.String("template", "templates", "")
+	verbose := fs.Bool("verbose", false, "")
+	spec := fs.String("spec", "", "")
+	env := map[string]string{
+		"OPENAPIGEN_OUTPUT":   "from-env",
+		"OPENAPIGEN_TEMPLATE": "env-templates",
+		"OPENAPIGEN_SPEC":     `{"openapi": "3.0.0"}`,
+	}
+	for name, value := range env {
+		defer func(name string, old string, ok bool) {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name, os.Getenv(name), os.Getenv(name) != "")
+		os.Setenv(name, value)
+	}
+	if err := fs.Parse([]string{"-template", "cli-templates"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnvFlags(fs); err != nil {
+		t.Fatalf("applyEnvFlags() failed: %v", err)
+	}
+	if *output != "from-env" {
+		t.Errorf("-output = %q, want it filled from $OPENAPIGEN_OUTPUT", *output)
+	}
+	if *template != "cli-templates" {
+		t.Errorf("-template = %q, want the command line to win over the environment", *template)
+	}
+	if *verbose {
+		t.Error("-verbose was set without a command-line flag or environment variable")
+	}
+	if *spec != "" {
+		t.Errorf("-spec = %q, want $OPENAPIGEN_SPEC left to the inline spec", *spec)
+	}
+
+	os.Setenv("OPENAPIGEN_VERBOSE", "maybe")
+	defer os.Unsetenv("OPENAPIGEN_VERBOSE")
+	if err := applyEnvFlags(fs); err == nil {
+		t.Error("applyEnvFlags() accepted an invalid boolean from $OPENAPIGEN_VERBOSE")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	tplText "text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v2"
+)
+
+const frontMatterDelim = "---"
+
+// frontMatter is the optional YAML block at the top of a template that
+// configures how it is rendered.
+type frontMatter struct {
+	// Output is a template, evaluated against the spec, naming the output
+	// file relative to the output directory.
+	Output string `yaml:"output"`
+	// Engine forces the template engine: "text" or "html".
+	Engine string `yaml:"engine"`
+	// Each renders the template once per "tag", "schema" or "path".
+	Each string `yaml:"each"`
+}
+
+// templateItem is the element being rendered when a template iterates with
+// the front-matter "each" key.
+type templateItem struct {
+	Name  string
+	Value interface{}
+}
+
+// parseFrontMatter splits the front-matter block off a template. The block
+// opens with a "---" line, closes with another one and may only set known
+// keys. Templates without such a block, like YAML templates opening with a
+// document marker, are returned unchanged with a nil frontMatter.
+func parseFrontMatter(tplRaw string) (*frontMatter, string, error) {
+	raw := strings.ReplaceAll(tplRaw, "\r\n", "\n")
+	if !strings.HasPrefix(raw, frontMatterDelim+"\n") {
+		return nil, tplRaw, nil
+	}
+	rest := raw[len(frontMatterDelim)+1:]
+	var header, body string
+	if end := strings.Index(rest, "\n"+frontMatterDelim+"\n"); end != -1 {
+		header, body = rest[:end], rest[end+len(frontMatterDelim)+2:]
+	} else if strings.HasSuffix(rest, "\n"+frontMatterDelim) {
+		header = rest[:len(rest)-len(frontMatterDelim)-1]
+	} else {
+		return nil, tplRaw, nil
+	}
+	fm := &frontMatter{}
+	if err := yaml.UnmarshalStrict([]byte(header), fm); err != nil {
+		if hasFrontMatterKey(header) {
+			return nil, "", fmt.Errorf("invalid front-matter: %w", err)
+		}
+		return nil, tplRaw, nil
+	}
+	if *fm == (frontMatter{}) {
+		return nil, tplRaw, nil
+	}
+	switch fm.Engine {
+	case "", "text", "html":
+	default:
+		return nil, "", fmt.Errorf("invalid front-matter engine %q", fm.Engine)
+	}
+	switch fm.Each {
+	case "":
+	case "tag", "schema", "path":
+		if fm.Output == "" {
+			return nil, "", fmt.Errorf("front-matter each %q requires output", fm.Each)
+		}
+	default:
+		return nil, "", fmt.Errorf("invalid front-matter each %q", fm.Each)
+	}
+	return fm, body, nil
+}
+
+// hasFrontMatterKey reports whether header is a YAML mapping setting any of
+// the front-matter keys, which marks it as a front-matter block even when
+// other keys are misspelled.
+func hasFrontMatterKey(header string) bool {
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal([]byte(header), &keys); err != nil {
+		return false
+	}
+	for _, key := range []string{"output", "engine", "each"} {
+		if _, ok := keys[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// templateItems lists the elements a template iterates over. A template
+// without iteration renders once with a nil item.
+func templateItems(swagger *openapi3.T, fm *frontMatter) []*templateItem {
+	if fm == nil {
+		return []*templateItem{nil}
+	}
+	switch fm.Each {
+	case "tag":
+		var items []*templateItem
+		for _, tag := range sortedTags(swagger) {
+			items = append(items, &templateItem{Name: tag.Name, Value: tag})
+		}
+		return items
+	case "schema":
+		schemas := componentSchemas(swagger)
+		names := allSchemaNames(swagger)
+		items := make([]*templateItem, 0, len(names))
+		for _, name := range names {
+			items = append(items, &templateItem{Name: name, Value: schemas[name]})
+		}
+		return items
+	case "path":
+		paths := make([]string, 0, len(swagger.Paths))
+		for path := range swagger.Paths {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		items := make([]*templateItem, 0, len(paths))
+		for _, path := range paths {
+			items = append(items, &templateItem{Name: path, Value: swagger.Paths[path]})
+		}
+		return items
+	}
+	return []*templateItem{nil}
+}
+
+// renderFrontMatterOutput evaluates the front-matter output template into a
+// file path that must stay within the output directory.
+func renderFrontMatterOutput(tpl *tplText.Template, swagger *openapi3.T) (string, error) {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, swagger); err != nil {
+		return "", fmt.Errorf("cannot render front-matter output: %w", err)
+	}
+	out := path.Clean(buf.String())
+	if out == "." || path.IsAbs(out) || out == ".." || strings.HasPrefix(out, "../") {
+		return "", fmt.Errorf("front-matter output rendered to invalid path %q", buf.String())
+	}
+	return filepath.FromSlash(out), nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		tpl      string
+		wantFM   *frontMatter
+		wantBody string
+	}{
+		{
+			name:     "front-matter",
+			tpl:      "---\noutput: models/{{ item.Name }}.go\neach: schema\n---\npackage models\n",
+			wantFM:   &frontMatter{Output: "models/{{ item.Name }}.go", Each: "schema"},
+			wantBody: "package models\n",
+		},
+		{
+			name:     "front-matter with CRLF",
+			tpl:      "---\r\nengine: html\r\n---\r\n<p></p>",
+			wantFM:   &frontMatter{Engine: "html"},
+			wantBody: "<p></p>",
+		},
+		{
+			name:     "front-matter without body",
+			tpl:      "---\nengine: text\n---",
+			wantFM:   &frontMatter{Engine: "text"},
+			wantBody: "",
+		},
+		{
+			name:     "no front-matter",
+			tpl:      "package main\n",
+			wantBody: "package main\n",
+		},
+		{
+			name:     "YAML document marker",
+			tpl:      "---\nopenapi: 3.0.0\ninfo:\n  title: {{ .Info.Title }}\n",
+			wantBody: "---\nopenapi: 3.0.0\ninfo:\n  title: {{ .Info.Title }}\n",
+		},
+		{
+			name:     "YAML documents",
+			tpl:      "---\nkind: Service\n---\nkind: Deployment\n",
+			wantBody: "---\nkind: Service\n---\nkind: Deployment\n",
+		},
+		{
+			name:     "empty YAML document",
+			tpl:      "---\n# nothing yet\n---\nkind: Deployment\n",
+			wantBody: "---\n# nothing yet\n---\nkind: Deployment\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := parseFrontMatter(tt.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (fm == nil) != (tt.wantFM == nil) || (fm != nil && *fm != *tt.wantFM) {
+				t.Errorf("front-matter = %+v, want %+v", fm, tt.wantFM)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+	for _, invalid := range []string{
+		"---\nengine: jinja\n---\nbody",
+		"---\neach: schema\n---\nbody",
+		"---\neach: operation\noutput: x\n---\nbody",
+		"---\noutput: x.go\nengin: html\n---\nbody",
+		"---\nouput: x.go\neach: schema\n---\nbody",
+	} {
+		if _, _, err := parseFrontMatter(invalid); err == nil {
+			t.Errorf("parseFrontMatter(%q) should fail", invalid)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "bytes"
+
+// stripJSONC turns a JSON-with-comments document into plain JSON by removing
+// line (//) and block (/* */) comments and trailing commas before closing
+// brackets. String literals are copied verbatim.
+func stripJSONC(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(src) {
+				i = len(src) - 1
+			}
+			out.Write(src[start : i+1])
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				out.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(src)
+				break
+			}
+			i += end + 3
+		case c == ',':
+			j := i + 1
+			for j < len(src) {
+				switch {
+				case bytes.IndexByte([]byte(" \t\r\n"), src[j]) >= 0:
+					j++
+					continue
+				case src[j] == '/' && j+1 < len(src) && src[j+1] == '/':
+					for j < len(src) && src[j] != '\n' {
+						j++
+					}
+					continue
+				case src[j] == '/' && j+1 < len(src) && src[j+1] == '*':
+					end := bytes.Index(src[j+2:], []byte("*/"))
+					if end < 0 {
+						j = len(src)
+					} else {
+						j += end + 4
+					}
+					continue
+				}
+				break
+			}
+			if j < len(src) && (src[j] == '}' || src[j] == ']') {
+				break
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
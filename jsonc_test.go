@@ -0,0 +1,43 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestParseSpecJSONC(t *testing.T) {
+	defer func(old string) { *specFormat = old }(*specFormat)
+	*specFormat = "jsonc"
+	raw := `{
+		// The pet store.
+		"openapi": "3.0.0",
+		"info": {"title": "Pets // not a comment", "version": "1"}, /* block */
+		"paths": {
+			"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}}}, // trailing
+		},
+	}`
+	swagger, version, err := parseSpec([]byte(raw))
+	if err != nil {
+		t.Fatalf("cannot parse JSONC spec: %v", err)
+	}
+	if version != "3.0.0" {
+		t.Errorf("version = %q, want 3.0.0", version)
+	}
+	if got := swagger.Info.Title; got != "Pets // not a comment" {
+		t.Errorf("title = %q, comment markers inside strings must be kept", got)
+	}
+	if op := swagger.Paths.Find("/pets"); op == nil || op.Get == nil || op.Get.OperationID != "listPets" {
+		t.Errorf("GET /pets was not loaded")
+	}
+}
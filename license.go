@@ -0,0 +1,103 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+const spdxTag = "SPDX-License-Identifier:"
+
+// commentStyle describes how to turn a license header into a comment.
+type commentStyle struct {
+	prefix, open, close string
+}
+
+// licenseCommentStyles maps output extensions to their comment syntax.
+// Outputs of other types, including JSON, are written without header.
+var licenseCommentStyles = map[string]commentStyle{
+	".go":    {prefix: "// "},
+	".js":    {prefix: "// "},
+	".ts":    {prefix: "// "},
+	".java":  {prefix: "// "},
+	".kt":    {prefix: "// "},
+	".swift": {prefix: "// "},
+	".rs":    {prefix: "// "},
+	".c":     {prefix: "// "},
+	".h":     {prefix: "// "},
+	".cpp":   {prefix: "// "},
+	".cs":    {prefix: "// "},
+	".proto": {prefix: "// "},
+	".py":    {prefix: "# "},
+	".rb":    {prefix: "# "},
+	".sh":    {prefix: "# "},
+	".yaml":  {prefix: "# "},
+	".yml":   {prefix: "# "},
+	".toml":  {prefix: "# "},
+	".sql":   {prefix: "-- "},
+	".html":  {open: "<!--", close: "-->"},
+	".xml":   {open: "<!--", close: "-->"},
+	".md":    {open: "<!--", close: "-->"},
+}
+
+// spdxFirst reorders the license header so that its SPDX-License-Identifier
+// line, if any, comes first.
+func spdxFirst(header string) []string {
+	lines := strings.Split(strings.TrimRight(strings.Replace(header, "\r\n", "\n", -1), "\n"), "\n")
+	for i, line := range lines {
+		if strings.Contains(line, spdxTag) {
+			reordered := append([]string{strings.TrimSpace(line)}, lines[:i]...)
+			lines = append(reordered, lines[i+1:]...)
+			break
+		}
+	}
+	for len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// prependLicense prefixes content with the license header, commented in the
+// syntax of the output file type. A leading shebang line stays first.
+func prependLicense(header, outputFn string, content []byte) []byte {
+	style, ok := licenseCommentStyles[strings.ToLower(filepath.Ext(outputFn))]
+	if header == "" || !ok {
+		return content
+	}
+	var buf bytes.Buffer
+	if bytes.HasPrefix(content, []byte("#!")) {
+		shebang, rest := content, []byte(nil)
+		if end := bytes.IndexByte(content, '\n'); end != -1 {
+			shebang, rest = content[:end], content[end+1:]
+		}
+		buf.Write(shebang)
+		buf.WriteString("\n")
+		content = rest
+	}
+	if style.open != "" {
+		buf.WriteString(style.open + "\n")
+	}
+	for _, line := range spdxFirst(header) {
+		buf.WriteString(strings.TrimRight(style.prefix+line, " ") + "\n")
+	}
+	if style.close != "" {
+		buf.WriteString(style.close + "\n")
+	}
+	buf.WriteString("\n")
+	buf.Write(content)
+	return buf.Bytes()
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestPrependLicense(t *testing.T) {
+	const header = "Copyright 2019 Example Inc.\n\nSPDX-License-Identifier: Apache-2.0\n"
+	tests := []struct {
+		fn, content, want string
+	}{
+		{
+			"models.go", "package models\n",
+			"// SPDX-License-Identifier: Apache-2.0\n// Copyright 2019 Example Inc.\n\npackage models\n",
+		},
+		{
+			"run.sh", "#!/bin/sh\necho hi\n",
+			"#!/bin/sh\n# SPDX-License-Identifier: Apache-2.0\n# Copyright 2019 Example Inc.\n\necho hi\n",
+		},
+		{
+			"run.sh", "#!/bin/sh",
+			"#!/bin/sh\n# SPDX-License-Identifier: Apache-2.0\n# Copyright 2019 Example Inc.\n\n",
+		},
+		{"spec.json", "{}", "{}"},
+	}
+	for _, tt := range tests {
+		if got := string(prependLicense(header, tt.fn, []byte(tt.content))); got != tt.want {
+			t.Errorf("prependLicense(%s) =\n%s\nwant\n%s", tt.fn, got, tt.want)
+		}
+	}
+}
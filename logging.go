@@ -0,0 +1,96 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// logEntry is the shape of each log line under -log-json.
+type logEntry struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	File  string `json:"file,omitempty"`
+}
+
+func logLine(level, msg, file string) {
+	msg = strings.TrimSpace(msg)
+	if !*logJSON {
+		if file != "" {
+			log.Println(msg, file)
+			return
+		}
+		log.Println(msg)
+		return
+	}
+	b, err := json.Marshal(logEntry{Level: level, Msg: msg, File: file})
+	if err != nil {
+		log.Println(msg, file)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", b)
+}
+
+// logInfo logs an informational message, optionally about a file.
+func logInfo(msg, file string) {
+	logLine("info", msg, file)
+}
+
+// logWarning logs a non-fatal issue, optionally about a file.
+func logWarning(msg, file string) {
+	if !*logJSON {
+		msg = "warning: " + msg
+	}
+	logLine("warning", msg, file)
+}
+
+// exitHooks run, last registered first, before the process exits, so that
+// profiles and traces are flushed on every exit path.
+var exitHooks []func()
+
+// atExit registers fn to run before the process exits.
+func atExit(fn func()) {
+	exitHooks = append(exitHooks, fn)
+}
+
+// runExitHooks runs and clears the registered exit hooks.
+func runExitHooks() {
+	for i := len(exitHooks) - 1; i >= 0; i-- {
+		exitHooks[i]()
+	}
+	exitHooks = nil
+}
+
+// exit runs the exit hooks and terminates the process with the given status.
+func exit(code int) {
+	runExitHooks()
+	os.Exit(code)
+}
+
+// fatal logs its arguments, formatted as log.Fatal does, and exits with a
+// nonzero status.
+func fatal(v ...interface{}) {
+	logLine("error", fmt.Sprint(v...), "")
+	exit(1)
+}
+
+// fatalf is the formatted variant of fatal.
+func fatalf(format string, v ...interface{}) {
+	fatal(fmt.Sprintf(format, v...))
+}
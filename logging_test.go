@@ -0,0 +1,76 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogJSON(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/title.txt.tpl": "{{ .Info.Title }}",
+	})
+	// logged runs openapigen with -log-json and returns the files of its
+	// log entries by message.
+	logged := func() map[string][]string {
+		t.Helper()
+		out, code := runOpenapigen(t, dir, nil, "-log-json", "-spec", "spec.json", "-template", "tpl", "-output", "out")
+		if code != 0 {
+			t.Fatalf("openapigen failed: %s", out)
+		}
+		files := make(map[string][]string)
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+			var entry logEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Errorf("log line %q is not JSON: %v", line, err)
+				continue
+			}
+			if entry.Level == "" || entry.Msg == "" {
+				t.Errorf("log line %q lacks level or msg", line)
+			}
+			files[entry.Msg] = append(files[entry.Msg], entry.File)
+		}
+		return files
+	}
+	first := logged()
+	if len(first["rendering"]) == 0 {
+		t.Errorf("no rendering entry found in %v", first)
+	}
+	if got := first["written"]; !reflect.DeepEqual(got, []string{"title.txt"}) {
+		t.Errorf("first run written entries = %v, want title.txt", got)
+	}
+	second := logged()
+	if got := second["unchanged"]; !reflect.DeepEqual(got, []string{"title.txt"}) {
+		t.Errorf("second run unchanged entries = %v, want title.txt", got)
+	}
+	if got := second["written"]; len(got) != 0 {
+		t.Errorf("second run written entries = %v, want none", got)
+	}
+	out, code := runOpenapigen(t, dir, nil, "-log-json", "-spec", "missing.json", "-template", "tpl")
+	if code == 0 {
+		t.Fatal("openapigen should fail on a missing spec")
+	}
+	var entry logEntry
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &entry); err != nil || entry.Level != "error" {
+		t.Errorf("fatal error was not logged as a JSON error entry: %q", out)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// lookupResult is the outcome of lookup: the element found, if any.
+type lookupResult struct {
+	Value interface{}
+	Found bool
+}
+
+// lookup indexes a map by key, or a slice or array by position. Unlike the
+// builtin index function, missing keys and out-of-range positions are not
+// errors: they yield a result whose Found field is false. Integer keys of
+// string-keyed maps are looked up in decimal, so status codes may be given
+// as numbers.
+func lookup(collection, key interface{}) (lookupResult, error) {
+	v := reflect.ValueOf(collection)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return lookupResult{}, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		k := reflect.ValueOf(key)
+		if !k.IsValid() {
+			return lookupResult{}, nil
+		}
+		if keyType := v.Type().Key(); keyType.Kind() == reflect.String {
+			switch k.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				k = reflect.ValueOf(strconv.FormatInt(k.Int(), 10))
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				k = reflect.ValueOf(strconv.FormatUint(k.Uint(), 10))
+			}
+		}
+		if !k.Type().ConvertibleTo(v.Type().Key()) {
+			return lookupResult{}, fmt.Errorf("lookup: cannot use %T as key of %s", key, v.Type())
+		}
+		elem := v.MapIndex(k.Convert(v.Type().Key()))
+		if !elem.IsValid() {
+			return lookupResult{}, nil
+		}
+		return lookupResult{Value: elem.Interface(), Found: true}, nil
+	case reflect.Slice, reflect.Array:
+		i, err := lookupIndex(key)
+		if err != nil {
+			return lookupResult{}, err
+		}
+		if i < 0 || i >= v.Len() {
+			return lookupResult{}, nil
+		}
+		return lookupResult{Value: v.Index(i).Interface(), Found: true}, nil
+	case reflect.Invalid:
+		return lookupResult{}, nil
+	}
+	return lookupResult{}, fmt.Errorf("lookup: cannot index %s", v.Type())
+}
+
+func lookupIndex(key interface{}) (int, error) {
+	switch k := key.(type) {
+	case int:
+		return k, nil
+	case int64:
+		return int(k), nil
+	case string:
+		i, err := strconv.Atoi(k)
+		if err != nil {
+			return 0, fmt.Errorf("lookup: invalid position %q", k)
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("lookup: invalid position %v", key)
+}
+
+// dig follows a chain of struct fields, map keys and slice positions from
+// v, returning nil as soon as an intermediate value is nil or missing
+// instead of failing like chained field access does. Unknown struct fields
+// are still reported as errors, as they are likely typos.
+func dig(v interface{}, path ...interface{}) (interface{}, error) {
+	cur := reflect.ValueOf(v)
+	for _, step := range path {
+		for cur.Kind() == reflect.Ptr || cur.Kind() == reflect.Interface {
+			if cur.IsNil() {
+				return nil, nil
+			}
+			cur = cur.Elem()
+		}
+		switch cur.Kind() {
+		case reflect.Invalid:
+			return nil, nil
+		case reflect.Struct:
+			name, ok := step.(string)
+			if !ok {
+				return nil, fmt.Errorf("dig: invalid field name %v of %s", step, cur.Type())
+			}
+			field := cur.FieldByName(name)
+			if !field.IsValid() {
+				return nil, fmt.Errorf("dig: %s has no field %s", cur.Type(), name)
+			}
+			cur = field
+		default:
+			r, err := lookup(cur.Interface(), step)
+			if err != nil {
+				return nil, err
+			}
+			if !r.Found {
+				return nil, nil
+			}
+			cur = reflect.ValueOf(r.Value)
+		}
+	}
+	if !cur.IsValid() {
+		return nil, nil
+	}
+	if (cur.Kind() == reflect.Ptr || cur.Kind() == reflect.Interface || cur.Kind() == reflect.Map || cur.Kind() == reflect.Slice) && cur.IsNil() {
+		return nil, nil
+	}
+	return cur.Interface(), nil
+}
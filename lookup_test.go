@@ -0,0 +1,90 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestLookup(t *testing.T) {
+	swagger := parseTestSpec(t, petstoreSpec, false)
+	responses := testOperation(t, swagger, "GET", "/pets").Responses
+	tags := []string{"pets", "store"}
+	tests := []struct {
+		name       string
+		collection interface{}
+		key        interface{}
+		wantFound  bool
+		wantValue  interface{}
+	}{
+		{"responses hit", responses, "200", true, responses["200"]},
+		{"responses miss", responses, "404", false, nil},
+		{"responses by number", responses, 200, true, responses["200"]},
+		{"slice hit", tags, 1, true, "store"},
+		{"slice position as string", tags, "0", true, "pets"},
+		{"slice out of range", tags, 2, false, nil},
+		{"slice negative", tags, -1, false, nil},
+		{"nil map", map[string]int(nil), "a", false, nil},
+	}
+	for _, tt := range tests {
+		got, err := lookup(tt.collection, tt.key)
+		if err != nil {
+			t.Errorf("%s: lookup failed: %v", tt.name, err)
+			continue
+		}
+		if got.Found != tt.wantFound || got.Value != tt.wantValue {
+			t.Errorf("%s: lookup() = %+v, want {Value:%v Found:%v}", tt.name, got, tt.wantValue, tt.wantFound)
+		}
+	}
+	if _, err := lookup(tags, "first"); err == nil {
+		t.Error("lookup with an invalid position should fail")
+	}
+	if _, err := lookup(responses, 2.5); err == nil {
+		t.Error("lookup with a mistyped key should fail")
+	}
+	if _, err := lookup(openapi3.Info{}, "Title"); err == nil {
+		t.Error("lookup into a struct should fail")
+	}
+}
+
+func TestDig(t *testing.T) {
+	swagger := parseTestSpec(t, petstoreSpec, false)
+	tests := []struct {
+		name string
+		path []interface{}
+		want interface{}
+	}{
+		{"present", []interface{}{"Paths", "/pets", "Get", "OperationID"}, "listPets"},
+		{"present through schema references", []interface{}{"Components", "Schemas", "Pet", "Value", "Type"}, "object"},
+		{"absent map key", []interface{}{"Paths", "/stores", "Get", "OperationID"}, nil},
+		{"nil pointer", []interface{}{"Paths", "/pets", "Post", "OperationID"}, nil},
+		{"nil field", []interface{}{"Info", "Contact", "Email"}, nil},
+	}
+	for _, tt := range tests {
+		got, err := dig(swagger, tt.path...)
+		if err != nil {
+			t.Errorf("%s: dig failed: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: dig() = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+	if _, err := dig(swagger, "Info", "Titel"); err == nil {
+		t.Error("dig into an unknown struct field should fail")
+	}
+}
@@ -12,12 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Command openapigen is an OpenAPI v2 renderer. Internally it uses Go's
+// Command openapigen is an OpenAPI v2 and v3 renderer. Internally it uses Go's
 // template engine to render the output.
 package main
 
 import (
+	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -26,101 +29,489 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime/pprof"
+	"runtime/trace"
 	"sort"
+	"strconv"
 	"strings"
 	tplText "text/template"
+	"time"
 
-	"github.com/getkin/kin-openapi/openapi2"
-	"github.com/getkin/kin-openapi/openapi2conv"
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/iancoleman/strcase"
 )
 
 var (
-	spec        = flag.String("spec", ".", "openAPI json filename")
-	isHTML      = flag.Bool("html", false, "use html/template")
-	template    = flag.String("template", "", "location of the template file")
-	output      = flag.String("output", "", "filename of the expected output")
-	isOpenAPIV2 = flag.Bool("v2mode", false, "indicates the spec is an openAPI v2 file")
-	view        = flag.Bool("view", false, "print parsed spec file")
+	spec            = flag.String("spec", ".", "openAPI json filename, or directory holding a single spec (comma-separated to render each spec into its own subdirectory)")
+	isHTML          = flag.Bool("html", false, "use html/template for every template (.html.tpl templates always use it)")
+	template        = flag.String("template", "", "location of the template directory or .zip archive")
+	output          = flag.String("output", "", "filename of the expected output; path segments may use template placeholders evaluated against the spec, e.g. gen/{{.Info.Title | snake}}")
+	isOpenAPIV2     = flag.Bool("v2mode", false, "indicates the spec is an openAPI v2 file")
+	view            = flag.Bool("view", false, "print parsed spec file")
+	cpuProfile      = flag.String("cpuprofile", "", "write a pprof CPU profile of the run to file")
+	maxDepth        = flag.Int("max-depth", 64, "maximum schema nesting depth walked by recursive helpers")
+	logJSON         = flag.Bool("log-json", false, "emit log lines as JSON objects")
+	traceFile       = flag.String("trace", "", "write an execution trace of the run to file")
+	specString      = flag.String("spec-string", "", "raw spec contents, used instead of -spec (also read from $OPENAPIGEN_SPEC when -spec is not given)")
+	specFormat      = flag.String("format", "json", "format of the spec file (json or jsonc)")
+	extMap          = flag.String("ext-map", "", "comma-separated list of template suffix remappings (e.g. .go.tpl=.ts)")
+	manifestFn      = flag.String("manifest", "", "write the list of generated files and their hashes to this file")
+	concat          = flag.String("concat", "", "render every template into this single file, relative to -output")
+	concatSeparator = flag.String("concat-separator", "\\n", "separator written between concatenated outputs (Go escape sequences allowed)")
+	minifyJSON      = flag.Bool("minify-json", false, "compact and validate outputs with a .json extension")
+	pluginFn        = flag.String("plugin", "", "Go plugin exporting Funcs() template.FuncMap with extra template functions; built-in functions take precedence")
+	seed            = flag.Int64("seed", 1, "seed of the randInt, randString and randFrom helpers")
+	skipEmpty       = flag.Bool("skip-empty", false, "do not write outputs whose rendering is blank")
+	clean           = flag.Bool("clean", false, "delete the files listed in the prior -manifest before writing the new outputs")
+	pruneStale      = flag.Bool("prune-stale", false, "delete files listed in the prior -manifest that were not generated in this run")
+	structTags      = flag.String("tags", "json", "comma-separated struct tag sets emitted by structTag (json, validate)")
+	diffMode        = flag.Bool("diff", false, "compare the two spec files given as arguments (-diff old.json new.json) and exit nonzero if they differ")
+	paginationFlag  = flag.String("pagination-params", "limit+offset,page+per_page,page+page_size,cursor", "comma-separated groups of query parameter names, joined by +, that mark an operation as paginated")
+	expandEnv       = flag.Bool("expand-env", false, "substitute ${NAME} placeholders in the spec with environment variables before parsing")
+	strictEnv       = flag.Bool("strict-env", false, "fail when -expand-env meets an undefined environment variable")
+	onMissingOpID   = flag.String("on-missing-operationid", "warn", "what to do with operations without operationId: fail, warn or synthesize")
+	trimPrefix      = flag.String("trim-path-prefix", "", "base path stripped from every spec path before rendering, e.g. /api/v1")
+	overwriteOrder  = flag.Bool("overwrite-order", false, "let templates rendering into the same output overwrite each other in walk order instead of failing")
+	keepGoing       = flag.Bool("keep-going", false, "keep rendering after a template fails and report every failure at the end, exiting with the number of failures")
+	onlyTag         = flag.String("only-tag", "", "render with the spec filtered down to the operations carrying this tag; schemas are kept")
+	licenseFn       = flag.String("license-header-file", "", "file whose contents are prepended, as a comment, to generated source files; an SPDX-License-Identifier line is moved first")
+	reportFn        = flag.String("report", "", "write a JSON summary of the run (templates rendered, files written, unchanged and skipped, warnings, duration) to this file")
+	failOnWarning   = flag.Bool("fail-on-warning", false, "exit with an error when the spec has warnings, such as operations without operationId")
 )
 
+var (
+	postCmds       stringList
+	schemaNameMaps stringList
+	dirMode        = octalMode{perm: 0755}
+	fileMode       = octalMode{perm: 0666}
+)
+
+func init() {
+	flag.Var(&postCmds, "post-cmd", "shell command to run in the output directory after rendering (repeatable)")
+	flag.Var(&dirMode, "dir-mode", "permission bits, in octal, of created directories, applied regardless of the umask (default: 0755 masked by the umask)")
+	flag.Var(&fileMode, "file-mode", "permission bits, in octal, of generated files, applied regardless of the umask (default: 0666 masked by the umask)")
+	flag.Var(&schemaNameMaps, "schema-name-map", "Go type name override for a schema, as oldName=NewName, applied by goName and goType only (repeatable)")
+}
+
 func main() {
+	start := time.Now()
 	flag.Parse()
 	log.SetFlags(0)
 	log.SetPrefix("openapigen: ")
-	fd, err := os.Open(*spec)
-	if err != nil {
-		log.Fatal("cannot open swagger json file:", err)
+	if err := applyEnvFlags(flag.CommandLine); err != nil {
+		fatal(err)
+	}
+	defer runExitHooks()
+	if *cpuProfile != "" {
+		fd, err := os.Create(*cpuProfile)
+		if err != nil {
+			fatal("cannot create CPU profile file:", err)
+		}
+		if err := pprof.StartCPUProfile(fd); err != nil {
+			fd.Close()
+			fatal("cannot start CPU profile:", err)
+		}
+		atExit(func() {
+			pprof.StopCPUProfile()
+			fd.Close()
+		})
+	}
+	if *traceFile != "" {
+		fd, err := os.Create(*traceFile)
+		if err != nil {
+			fatal("cannot create trace file:", err)
+		}
+		if err := trace.Start(fd); err != nil {
+			fd.Close()
+			fatal("cannot start execution trace:", err)
+		}
+		atExit(func() {
+			trace.Stop()
+			fd.Close()
+		})
 	}
-	var swagger *openapi3.T
-	if *isOpenAPIV2 {
-		log.Println("Decoding spec file with https://godoc.org/github.com/getkin/kin-openapi/openapi2#T")
-		var swaggerV2 openapi2.T
-		err := json.NewDecoder(fd).Decode(&swaggerV2)
+	if *diffMode {
+		if flag.NArg() != 2 {
+			fatal("-diff expects two spec files: -diff old.json new.json")
+		}
+		oldSpec, err := loadSpecFile(flag.Arg(0))
 		if err != nil {
-			log.Fatal("cannot parse swaggerV2 json file:", err)
+			fatal(err)
 		}
-		swagger, err = openapi2conv.ToV3(&swaggerV2)
+		newSpec, err := loadSpecFile(flag.Arg(1))
 		if err != nil {
-			log.Fatal("cannot convert from v2 to v3:", err)
+			fatal(err)
+		}
+		changes := specDiff(oldSpec.swagger, newSpec.swagger)
+		for _, change := range changes {
+			fmt.Println(change)
 		}
+		if len(changes) > 0 {
+			exit(1)
+		}
+		exit(0)
+	}
+	specs, err := loadSpecs()
+	if err != nil {
+		fatal(err)
+	}
+	for _, s := range specs {
+		if err := trimPathPrefix(s.swagger, *trimPrefix); err != nil {
+			fatal(s.name+":", err)
+		}
+		if *onlyTag != "" {
+			filterByTag(s.swagger, *onlyTag)
+		}
+		switch *onMissingOpID {
+		case "warn":
+			// Nothing to do here: specWarnings, run through warnSpecs below,
+			// reports every operation without operationId.
+		case "fail":
+			if missing := operationsWithoutID(s.swagger); len(missing) > 0 {
+				fatalf("%s: operations without operationId: %s", s.name, strings.Join(missing, ", "))
+			}
+		case "synthesize":
+			synthesizeOperationIDs(s.swagger)
+		default:
+			fatalf("invalid -on-missing-operationid %q (expected fail, warn or synthesize)", *onMissingOpID)
+		}
+	}
+	report := &runReport{Warnings: warnSpecs(specs)}
+	if report.Warnings > 0 && *failOnWarning {
+		fatalf("%d spec warning(s) found", report.Warnings)
 	}
 	if *view {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "	")
-		err := enc.Encode(swagger)
+		for _, s := range specs {
+			err := enc.Encode(s.swagger)
+			if err != nil {
+				fatal("cannot encode spec file")
+			}
+		}
+		exit(0)
+	}
+	var (
+		swagger        *openapi3.T
+		swaggerVersion string
+	)
+	var pluginFuncs map[string]interface{}
+	if *pluginFn != "" {
+		pluginFuncs, err = loadPluginFuncs(*pluginFn)
 		if err != nil {
-			log.Fatal("cannot encode spec file")
+			fatal("cannot load plugin:", err)
 		}
-		os.Exit(0)
+	}
+	extRemap, err := parseExtMap(*extMap)
+	if err != nil {
+		fatal("cannot parse extension map:", err)
+	}
+	schemaNameOverrides, err = parseSchemaNameMap(schemaNameMaps)
+	if err != nil {
+		fatal("cannot parse -schema-name-map:", err)
+	}
+	tagSets, err := parseStructTagSets(*structTags)
+	if err != nil {
+		fatal("cannot parse -tags:", err)
+	}
+	paginationGroups, err := parsePaginationParams(*paginationFlag)
+	if err != nil {
+		fatal("cannot parse -pagination-params:", err)
 	}
 	wd, err := os.Getwd()
 	if err != nil {
-		log.Fatal("cannot detect current working directory:", err)
+		fatal("cannot detect current working directory:", err)
 	}
 	templateDir, err := filepath.Abs(*template)
 	if err != nil {
-		log.Fatal("cannot calculate absolute directory for template:", err)
+		fatal("cannot calculate absolute directory for template:", err)
+	}
+	outputBase, outputTpl := splitOutputTemplate(*output)
+	if outputTpl != "" {
+		dirs := make(map[string]bool)
+		for i := range specs {
+			specs[i].dir, err = renderOutputDir(outputTpl, specs[i].swagger)
+			if err != nil {
+				fatal("cannot calculate output directory:", err)
+			}
+			if dirs[specs[i].dir] {
+				fatalf("more than one spec renders into %s", specs[i].dir)
+			}
+			dirs[specs[i].dir] = true
+		}
 	}
-	outputDir, err := filepath.Abs(*output)
+	outputDir, err := filepath.Abs(outputBase)
 	if err != nil {
-		log.Fatal("cannot calculate absolute directory for output:", err)
+		fatal("cannot calculate absolute directory for output:", err)
+	}
+	if *pruneStale && *manifestFn == "" {
+		fatal("-prune-stale requires -manifest")
 	}
-	err = filepath.Walk(templateDir, func(path string, info os.FileInfo, err error) error {
+	if *clean && *manifestFn == "" {
+		fatal("-clean requires -manifest")
+	}
+	var priorManifest *manifest
+	if *manifestFn != "" {
+		priorManifest, err = readManifest(*manifestFn)
 		if err != nil {
-			return err
+			fatal("cannot load prior manifest:", err)
 		}
-		if filepath.Ext(path) != ".tpl" || (filepath.Ext(path) == ".tpl" && info.IsDir()) {
-			return nil
-		}
-		relpath, err := filepath.Rel(wd, path)
+	}
+	var licenseHeader string
+	if *licenseFn != "" {
+		b, err := ioutil.ReadFile(*licenseFn)
 		if err != nil {
-			return fmt.Errorf("cannot calculate relative directory for %s: %w", path, err)
+			fatal("cannot read license header:", err)
 		}
-		log.Println("rendering", relpath)
-		tplRaw, err := readFile(path)
+		licenseHeader = string(b)
+	}
+	currentManifest := &manifest{}
+	// Outputs are rendered into a staging directory inside the output
+	// directory, and only moved into place once every template succeeded.
+	// Staying on the same filesystem lets the files be renamed into place,
+	// one by one.
+	// A run that fails before moving its outputs into place removes the
+	// directories it created for them, so the output tree is left untouched.
+	createdDir := topmostMissingDir(outputDir)
+	moved := false
+	if err := mkdirAll(outputDir, dirMode); err != nil {
+		fatal("cannot create output directory:", err)
+	}
+	stagingDir, err := ioutil.TempDir(outputDir, ".openapigen-staging-")
+	if err != nil {
+		os.RemoveAll(createdDir)
+		fatal("cannot create staging directory:", err)
+	}
+	atExit(func() {
+		os.RemoveAll(stagingDir)
+		if createdDir != "" && !moved {
+			os.RemoveAll(createdDir)
+		}
+	})
+	// writtenBy maps each output, relative to the output directory, to the
+	// template that rendered it, so collisions are caught across the run.
+	writtenBy := make(map[string]string)
+	// writeStaged writes content to the staging directory at outputRelpath
+	// and records it in the manifest.
+	writeStaged := func(source, outputRelpath string, content []byte) error {
+		if other, ok := writtenBy[outputRelpath]; ok {
+			if !*overwriteOrder {
+				return fmt.Errorf("%s and %s both render into %s (use -overwrite-order to let the last one win)", other, source, outputRelpath)
+			}
+			logInfo(fmt.Sprintf("%s overwrites output of %s:", source, other), outputRelpath)
+		}
+		writtenBy[outputRelpath] = source
+		content = prependLicense(licenseHeader, outputRelpath, content)
+		if *minifyJSON && filepath.Ext(outputRelpath) == ".json" {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, content); err != nil {
+				return fmt.Errorf("cannot minify %s: %w", outputRelpath, err)
+			}
+			content = buf.Bytes()
+		}
+		outputFn := filepath.Join(stagingDir, outputRelpath)
+		dir := filepath.Dir(outputFn)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			if err := os.MkdirAll(dir, dirMode.perm); err != nil {
+				return fmt.Errorf("cannot create directory %s: %w", dir, err)
+			}
+		}
+		if err := ioutil.WriteFile(outputFn, content, fileMode.perm); err != nil {
+			return fmt.Errorf("cannot create output file: %w", err)
+		}
+		if err := fileMode.chmod(outputFn); err != nil {
+			return fmt.Errorf("cannot set mode of output file: %w", err)
+		}
+		hash := sha256.Sum256(content)
+		currentManifest.add(filepath.ToSlash(outputRelpath), hex.EncodeToString(hash[:]))
+		return nil
+	}
+	var (
+		specDir     string
+		concatParts []concatPart
+	)
+	// render executes the template found at path, relative to the template
+	// root, into the matching location in the output directory.
+	render := func(path, tplRaw string) error {
+		fm, tplRaw, err := parseFrontMatter(tplRaw)
 		if err != nil {
-			return fmt.Errorf("cannot load template: %w", err)
+			return fmt.Errorf("cannot render %s: %w", path, err)
 		}
 		var tpl interface {
 			Execute(wr io.Writer, data interface{}) error
 		}
+		var (
+			skipped bool
+			item    *templateItem
+		)
 		funcs := map[string]interface{}{
+			"skip": func() string {
+				skipped = true
+				return ""
+			},
+			"item": func() *templateItem {
+				return item
+			},
 			"firstLetter": func(s string) string {
 				if len(s) == 0 {
 					return ""
 				}
 				return string(s[0])
 			},
-			"toLower":    strings.ToLower,
-			"camel":      strcase.ToCamel,
-			"lowerCamel": strcase.ToLowerCamel,
-			"snake":      strcase.ToSnake,
+			"toLower":     strings.ToLower,
+			"camel":       toCamel,
+			"lowerCamel":  toLowerCamel,
+			"snake":       toSnake,
+			"oneLine":     oneLine,
+			"goString":    goString,
+			"goRawString": goRawString,
+			"humanize":    humanize,
+			"title":       title,
 			"stripDefinitionPrefix": func(s string) string {
 				return strings.TrimPrefix(s, "#/definitions/")
 			},
+			"normalizeRef": refName,
+			"schemaRef": func(name string) string {
+				return schemaRef(name, *isOpenAPIV2)
+			},
+			"goImports": func(schemas ...interface{}) []string {
+				return goImports(swagger, schemas...)
+			},
+			"goName":              goName,
+			"goType":              goType,
+			"schemaType":          schemaType,
+			"numericType":         numericType,
+			"isOpenMap":           isOpenMap,
+			"disallowsAdditional": disallowsAdditional,
+			"anyOfMembers":        anyOfMembers,
+			"isEmptyObject":       isEmptyObject,
+			"isFreeForm":          isFreeForm,
+			"mapValueType":        mapValueType,
+			"resolveSchema": func(v interface{}) (*openapi3.Schema, error) {
+				return resolveSchema(v, *maxDepth)
+			},
+			"exampleFor": func(v interface{}) (interface{}, error) {
+				return exampleFor(v, *maxDepth)
+			},
+			"depthGuard": func(depth int) (int, error) {
+				return depthGuard(depth, *maxDepth)
+			},
+			"constraints": constraints,
+
+			"hasDefault":   hasDefault,
+			"defaultValue": defaultValue,
+			"isReadOnly":   isReadOnly,
+			"isWriteOnly":  isWriteOnly,
+			"structTag": func(v interface{}, propName string) string {
+				return structTag(tagSets, v, propName)
+			},
+			"arrayItems":         arrayItems,
+			"uniqueItems":        uniqueItems,
+			"itemsEnum":          itemsEnum,
+			"requestProperties":  requestProperties,
+			"responseProperties": responseProperties,
+
+			"referencedSchemas": func() []string {
+				return referencedSchemas(swagger)
+			},
+			"schemasByExtension": func(extension string) map[string][]string {
+				return schemasByExtension(swagger, extension)
+			},
+			"schemaTitle": func(v interface{}) string {
+				return schemaTitle(swagger, v)
+			},
+			"schemaDescription": func(v interface{}) string {
+				return schemaDescription(swagger, v)
+			},
+
+			"requestBodySchema":      requestBodySchema,
+			"responseSchema":         responseSchema,
+			"anyContentSchema":       anyContentSchema,
+			"requestBodyRequired":    requestBodyRequired,
+			"requestBodyDescription": requestBodyDescription,
+
+			"httpMethodConst": httpMethodConst,
+			"isSafe":          isSafe,
+			"isIdempotent":    isIdempotent,
+			"allMethods":      allMethods,
+
+			"returnsArray":          returnsArray,
+			"returnsArrayItem":      returnsArrayItem,
+			"successSchemas":        successSchemas,
+			"singleSuccessSchema":   singleSuccessSchema,
+			"defaultResponse":       defaultResponse,
+			"defaultResponseSchema": defaultResponseSchema,
+			"errorResponses":        errorResponses,
+			"successResponses":      successResponses,
+			"operationHash":         operationHash,
+			"isBinaryResponse":      isBinaryResponse,
+			"hasNoContent":          hasNoContent,
+
+			"commonPathPrefix": func(args ...interface{}) (string, error) {
+				return commonPathPrefix(swagger, args...)
+			},
+			"operationsByPathPrefix": func(n ...int) (map[string][]pathOperation, error) {
+				return operationsByPathPrefix(swagger, n...)
+			},
+			"pathToFilename": pathToFilename,
+			"routePath":      routePath,
+
+			"hasBody": hasBody,
+			"hasQuery": func(operation *openapi3.Operation) bool {
+				return hasParamsIn(swagger, operation, openapi3.ParameterInQuery)
+			},
+			"hasPathParams": func(operation *openapi3.Operation) bool {
+				return hasParamsIn(swagger, operation, openapi3.ParameterInPath)
+			},
+			"hasHeaders": func(operation *openapi3.Operation) bool {
+				return hasParamsIn(swagger, operation, openapi3.ParameterInHeader)
+			},
+
+			"paramRequired":         paramRequired,
+			"paramDeprecated":       paramDeprecated,
+			"paramExample":          paramExample,
+			"isArrayParam":          isArrayParam,
+			"paramCollectionFormat": paramCollectionFormat,
+			"paramSchema":           paramSchema,
+			"isPaginated": func(operation *openapi3.Operation) bool {
+				return len(paginationParams(swagger, operation, paginationGroups)) > 0
+			},
+			"paginationParams": func(operation *openapi3.Operation) []*openapi3.Parameter {
+				return paginationParams(swagger, operation, paginationGroups)
+			},
+			"queryParamsSchema": func(operation *openapi3.Operation) *openapi3.Schema {
+				return paramsSchema(swagger, operation, openapi3.ParameterInQuery)
+			},
+			"headerParamsSchema": func(operation *openapi3.Operation) *openapi3.Schema {
+				return paramsSchema(swagger, operation, openapi3.ParameterInHeader)
+			},
+
+			"isFileUpload":      isFileUpload,
+			"fileUploadFields":  fileUploadFields,
+			"operationConsumes": operationConsumes,
+			"operationProduces": operationProduces,
+			"usesOnlyJSON": func() bool {
+				return usesOnlyJSON(swagger)
+			},
+			"operationCallbacks": operationCallbacks,
+			"responseLinks":      responseLinks,
+			"operationSecurityRequirements": func(operation *openapi3.Operation) [][]securityRequirement {
+				return operationSecurityRequirements(swagger, operation)
+			},
+
+			"toJSON": func(v interface{}) (string, error) {
+				b, err := json.Marshal(v)
+				if err != nil {
+					return "", fmt.Errorf("cannot encode to JSON: %w", err)
+				}
+				return string(b), nil
+			},
+			"base64enc": base64Encode,
+			"base64dec": base64Decode,
+			"lookup":    lookup,
+			"dig":       dig,
 			"debug": func(v interface{}) (string, error) {
 				var buf bytes.Buffer
 				enc := json.NewEncoder(&buf)
@@ -131,80 +522,429 @@ func main() {
 				}
 				return buf.String(), nil
 			},
+			"specVersion": func() string {
+				return swaggerVersion
+			},
+			"isV2": func() bool {
+				return isSpecVersion(swaggerVersion, "2")
+			},
+			"isV3": func() bool {
+				return isSpecVersion(swaggerVersion, "3")
+			},
+			"isV31": func() bool {
+				return isSpecVersion(swaggerVersion, "3.1")
+			},
+			"componentResponse": func(name string) *openapi3.Response {
+				return componentResponse(swagger, name)
+			},
+			"componentParameter": func(name string) *openapi3.Parameter {
+				return componentParameter(swagger, name)
+			},
+			"allSchemaNames": func() []string {
+				return allSchemaNames(swagger)
+			},
+			"sortedSchemas": func() []namedSchema {
+				return sortedSchemas(swagger)
+			},
+			"sortedComponentResponses": func() []namedResponse {
+				return sortedComponentResponses(swagger)
+			},
+			"sortedComponentParameters": func() []namedParameter {
+				return sortedComponentParameters(swagger)
+			},
 			"uniquePathTags": func() []string {
-				var tags []string
-				for _, pathItem := range swagger.Paths {
-					if pathItem.Connect != nil {
-						tags = append(tags, pathItem.Connect.Tags...)
-					}
-					if pathItem.Delete != nil {
-						tags = append(tags, pathItem.Delete.Tags...)
-					}
-					if pathItem.Get != nil {
-						tags = append(tags, pathItem.Get.Tags...)
-					}
-					if pathItem.Head != nil {
-						tags = append(tags, pathItem.Head.Tags...)
-					}
-					if pathItem.Options != nil {
-						tags = append(tags, pathItem.Options.Tags...)
-					}
-					if pathItem.Patch != nil {
-						tags = append(tags, pathItem.Patch.Tags...)
-					}
-					if pathItem.Post != nil {
-						tags = append(tags, pathItem.Post.Tags...)
-					}
-					if pathItem.Put != nil {
-						tags = append(tags, pathItem.Put.Tags...)
-					}
-					if pathItem.Trace != nil {
-						tags = append(tags, pathItem.Trace.Tags...)
-					}
-				}
-				tagsDict := make(map[string]struct{})
-				for _, tag := range tags {
-					tagsDict[tag] = struct{}{}
-				}
-				uniqTags := []string{}
-				for tag := range tagsDict {
-					uniqTags = append(uniqTags, tag)
-				}
-				sort.Strings(uniqTags)
-				return uniqTags
+				return uniquePathTags(swagger)
+			},
+			"tagInfo": func(name string) *openapi3.Tag {
+				return tagInfo(swagger, name)
 			},
+			"sortedTags": func() []*openapi3.Tag {
+				return sortedTags(swagger)
+			},
+			"operationTags": operationTags,
+			"primaryTag":    primaryTag,
+		}
+		for name, fn := range randFuncs(templateRand(*seed, path)) {
+			funcs[name] = fn
+		}
+		for name, fn := range pluginFuncs {
+			if _, ok := funcs[name]; !ok {
+				funcs[name] = fn
+			}
+		}
+		isHTMLTemplate := *isHTML || strings.HasSuffix(path, ".html.tpl")
+		if fm != nil && fm.Engine != "" {
+			isHTMLTemplate = fm.Engine == "html"
 		}
 		switch {
-		case *isHTML:
-			tpl, err = tplHTML.New("openapigen").Funcs(tplHTML.FuncMap(funcs)).Option("missingkey=zero").Parse(tplRaw)
+		case isHTMLTemplate:
+			tpl, err = tplHTML.New(path).Funcs(tplHTML.FuncMap(funcs)).Option("missingkey=zero").Parse(tplRaw)
 			if err != nil {
 				return fmt.Errorf("cannot parse template (html mode): %w", err)
 			}
 		default:
-			tpl, err = tplText.New("openapigen").Funcs(tplText.FuncMap(funcs)).Option("missingkey=zero").Parse(tplRaw)
+			tpl, err = tplText.New(path).Funcs(tplText.FuncMap(funcs)).Option("missingkey=zero").Parse(tplRaw)
 			if err != nil {
 				return fmt.Errorf("cannot parse template (text mode): %w", err)
 			}
 		}
-		dir := filepath.Dir(filepath.Join(outputDir, strings.TrimPrefix(path, templateDir)))
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			if err := os.MkdirAll(dir, os.ModePerm&0755); err != nil {
-				return fmt.Errorf("cannot create directory %s: %w", dir, err)
+		var outputTpl *tplText.Template
+		if fm != nil && fm.Output != "" {
+			outputTpl, err = tplText.New(path + " output").Funcs(tplText.FuncMap(funcs)).Option("missingkey=error").Parse(fm.Output)
+			if err != nil {
+				return fmt.Errorf("cannot parse front-matter output: %w", err)
+			}
+		}
+		for _, item = range templateItems(swagger, fm) {
+			skipped = false
+			name := path
+			if item != nil {
+				name = path + "#" + item.Name
+			}
+			var buf bytes.Buffer
+			if err := tpl.Execute(&buf, swagger); err != nil {
+				return fmt.Errorf("cannot render output: %w", err)
 			}
+			if skipped || (*skipEmpty && len(bytes.TrimSpace(buf.Bytes())) == 0) {
+				logInfo("skipping", name)
+				report.FilesSkipped++
+				continue
+			}
+			if *concat != "" {
+				concatParts = append(concatParts, concatPart{path: name, content: buf.Bytes()})
+				continue
+			}
+			out := filepath.Join(filepath.Dir(filepath.FromSlash(path)), outputName(filepath.Base(path), extRemap))
+			if outputTpl != nil {
+				out, err = renderFrontMatterOutput(outputTpl, swagger)
+				if err != nil {
+					return fmt.Errorf("cannot render %s: %w", name, err)
+				}
+			}
+			if err := writeStaged(name, filepath.Join(specDir, out), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var failures []templateFailure
+	renderOrRecord := func(name, tplRaw string) error {
+		err := render(name, tplRaw)
+		if err == nil {
+			report.TemplatesRendered++
+		}
+		if err != nil && *keepGoing {
+			failures = append(failures, templateFailure{name: path.Join(filepath.ToSlash(specDir), name), err: err})
+			return nil
+		}
+		return err
+	}
+	for _, s := range specs {
+		swagger, swaggerVersion, specDir, concatParts = s.swagger, s.version, s.dir, nil
+		err = walkTemplates(templateDir, wd, renderOrRecord)
+		if err == nil && *concat != "" {
+			err = writeStaged("-concat", filepath.Join(specDir, filepath.FromSlash(*concat)), joinConcatParts(concatParts, *concatSeparator))
 		}
-		fd, err := os.Create(strings.TrimSuffix(filepath.Join(dir, filepath.Base(path)), ".tpl"))
 		if err != nil {
-			return fmt.Errorf("cannot create output file: %w", err)
+			break
+		}
+	}
+	if err != nil {
+		fatal("cannot iterate through template files:", err)
+	}
+	if len(failures) > 0 {
+		logLine("error", failureReport(failures), "")
+		exit(failureExitCode(len(failures)))
+	}
+	if *clean {
+		logInfo("cleaning", outputDir)
+		if err := cleanManifest(outputDir, priorManifest); err != nil {
+			fatal("cannot clean output directory:", err)
 		}
-		defer fd.Close()
-		if err := tpl.Execute(fd, swagger); err != nil {
-			return fmt.Errorf("cannot render output: %w", err)
+	}
+	err = moveStaged(stagingDir, outputDir, currentManifest, dirMode, report)
+	os.RemoveAll(stagingDir)
+	if err != nil {
+		fatal("cannot move rendered files into output directory:", err)
+	}
+	moved = true
+	if *pruneStale {
+		pruned, err := pruneStaleFiles(outputDir, priorManifest, currentManifest)
+		for _, fn := range pruned {
+			logInfo("pruned", fn)
 		}
-		return nil
+		if err != nil {
+			fatal("cannot prune stale files:", err)
+		}
+	}
+	if *manifestFn != "" {
+		if err := writeManifest(*manifestFn, currentManifest); err != nil {
+			fatal("cannot write manifest:", err)
+		}
+	}
+	for _, postCmd := range postCmds {
+		logInfo("running "+postCmd, "")
+		if err := runPostCmd(outputDir, postCmd); err != nil {
+			fatal("post command failed:", err)
+		}
+	}
+	if *reportFn != "" {
+		if err := writeReport(*reportFn, report, start); err != nil {
+			fatal("cannot write report:", err)
+		}
+	}
+}
+
+// splitOutputTemplate splits the output path into its static leading
+// segments and the remaining segments that contain template placeholders.
+func splitOutputTemplate(output string) (base, tpl string) {
+	segments := strings.Split(filepath.ToSlash(output), "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "{{") {
+			base = strings.Join(segments[:i], "/")
+			if base == "" && i > 0 {
+				base = "/"
+			}
+			return filepath.FromSlash(base), strings.Join(segments[i:], "/")
+		}
+	}
+	return output, ""
+}
+
+// renderOutputDir evaluates the templated part of the output path against
+// the spec. The result must stay within the static part of the output path.
+func renderOutputDir(tpl string, swagger *openapi3.T) (string, error) {
+	t, err := tplText.New("output").Funcs(tplText.FuncMap{
+		"toLower":    strings.ToLower,
+		"camel":      toCamel,
+		"lowerCamel": toLowerCamel,
+		"snake":      toSnake,
+	}).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse output template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, swagger); err != nil {
+		return "", fmt.Errorf("cannot render output template: %w", err)
+	}
+	dir := path.Clean(buf.String())
+	if dir == "." || path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("output template %q rendered to invalid directory %q", tpl, buf.String())
+	}
+	return filepath.FromSlash(dir), nil
+}
+
+// walkTemplates calls fn for every template found in templateDir, which is
+// either a directory or a .zip archive. Template names are slash-separated
+// and relative to templateDir.
+func walkTemplates(templateDir, wd string, fn func(name, tplRaw string) error) error {
+	if filepath.Ext(templateDir) == ".zip" {
+		return walkZipTemplates(templateDir, func(name, tplRaw string) error {
+			logInfo("rendering", filepath.Base(templateDir)+":"+name)
+			return fn(name, tplRaw)
+		})
+	}
+	return filepath.Walk(templateDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) != ".tpl" || (filepath.Ext(path) == ".tpl" && info.IsDir()) {
+			return nil
+		}
+		relpath, err := filepath.Rel(wd, path)
+		if err != nil {
+			return fmt.Errorf("cannot calculate relative directory for %s: %w", path, err)
+		}
+		logInfo("rendering", relpath)
+		tplRaw, err := readFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot load template: %w", err)
+		}
+		name, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return fmt.Errorf("cannot calculate relative directory for %s: %w", path, err)
+		}
+		if name == "." {
+			name = filepath.Base(path)
+		}
+		return fn(filepath.ToSlash(name), tplRaw)
 	})
+}
+
+// parseExtMap parses a comma-separated list of suffix=replacement pairs.
+func parseExtMap(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || !strings.HasSuffix(kv[0], ".tpl") {
+			return nil, fmt.Errorf("invalid remapping %q: expected form .ext.tpl=.newext", pair)
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m, nil
+}
+
+// outputName calculates the output filename of a template. The longest
+// matching suffix in extRemap is replaced, otherwise the .tpl suffix is
+// simply removed.
+func outputName(base string, extRemap map[string]string) string {
+	var match string
+	for suffix := range extRemap {
+		if strings.HasSuffix(base, suffix) && len(suffix) > len(match) {
+			match = suffix
+		}
+	}
+	if match == "" {
+		return strings.TrimSuffix(base, ".tpl")
+	}
+	return strings.TrimSuffix(base, match) + extRemap[match]
+}
+
+// concatPart is the rendering of a single template under -concat.
+type concatPart struct {
+	path    string
+	content []byte
+}
+
+// joinConcatParts joins the renderings in template path order. Go escape
+// sequences in sep are interpreted.
+func joinConcatParts(parts []concatPart, sep string) []byte {
+	if unquoted, err := strconv.Unquote(`"` + sep + `"`); err == nil {
+		sep = unquoted
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].path < parts[j].path })
+	var buf bytes.Buffer
+	for i, part := range parts {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		buf.Write(part.content)
+	}
+	return buf.Bytes()
+}
+
+// moveStaged moves the files listed in m from stagingDir into outputDir,
+// creating missing directories with dirMode, and tallies them in r.
+// Hand-edited files are not overwritten.
+func moveStaged(stagingDir, outputDir string, m *manifest, dirMode octalMode, r *runReport) error {
+	for _, f := range m.Files {
+		dst := filepath.Join(outputDir, filepath.FromSlash(f.Path))
+		if isEdited(dst) {
+			logWarning("preserving hand-edited file", dst)
+			r.FilesSkipped++
+			continue
+		}
+		if sameContent(dst, f.SHA256) {
+			logInfo("unchanged", f.Path)
+			r.FilesUnchanged++
+		} else {
+			logInfo("written", f.Path)
+			r.FilesWritten++
+		}
+		if err := mkdirAll(filepath.Dir(dst), dirMode); err != nil {
+			return fmt.Errorf("cannot create directory %s: %w", filepath.Dir(dst), err)
+		}
+		if err := os.Rename(filepath.Join(stagingDir, filepath.FromSlash(f.Path)), dst); err != nil {
+			return fmt.Errorf("cannot move %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// topmostMissingDir returns the outermost directory that creating dir would
+// create, or an empty string when dir already exists.
+func topmostMissingDir(dir string) string {
+	var missing string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = d
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return missing
+}
+
+// mkdirAll creates dir along with any missing parents, as os.MkdirAll does,
+// and applies mode to the directories it creates.
+func mkdirAll(dir string, mode octalMode) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, mode.perm); err != nil {
+		return err
+	}
+	for _, d := range missing {
+		if err := mode.chmod(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPostCmd runs the given shell command in dir. Its stderr is forwarded to
+// the log on success and embedded in the error on failure.
+func runPostCmd(dir, postCmd string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", postCmd)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", postCmd, err, strings.TrimSpace(stderr.String()))
+	}
+	if stderr.Len() > 0 {
+		logInfo(stderr.String(), "")
+	}
+	return nil
+}
+
+// walkZipTemplates calls fn, in name order, for every .tpl entry of the zip
+// archive.
+func walkZipTemplates(archive string, fn func(name, tplRaw string) error) error {
+	zr, err := zip.OpenReader(archive)
 	if err != nil {
-		log.Fatal("cannot iterate through template files:", err)
+		return fmt.Errorf("cannot open template archive: %w", err)
+	}
+	defer zr.Close()
+	files := append([]*zip.File(nil), zr.File...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	for _, f := range files {
+		if path.Ext(f.Name) != ".tpl" || f.FileInfo().IsDir() {
+			continue
+		}
+		if name := path.Clean(f.Name); path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("template %s escapes the output directory", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("cannot open %s: %w", f.Name, err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("cannot load template %s: %w", f.Name, err)
+		}
+		if err := fn(f.Name, string(b)); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func readFile(fn string) (string, error) {
@@ -0,0 +1,470 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// runMainEnv, when set, makes the test binary behave as openapigen itself,
+// so tests can exercise the command end to end in a child process.
+const runMainEnv = "GO_WANT_OPENAPIGEN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runOpenapigen runs openapigen with args in dir and returns its combined
+// output and exit status. The OPENAPIGEN_* variables of the test environment
+// are dropped in favor of env.
+func runOpenapigen(t *testing.T, dir string, env []string, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, envFlagPrefix) {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	cmd.Env = append(append(cmd.Env, runMainEnv+"=1"), env...)
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(out), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("cannot run openapigen: %v", err)
+	}
+	return string(out), 0
+}
+
+// writeTestFiles creates the given files, keyed by slash-separated path,
+// under dir.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fn := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readTestFile returns the contents of the file, failing the test when it
+// cannot be read.
+func readTestFile(t *testing.T, fn string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// tempDir creates a temporary directory for the test. Callers remove it.
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "openapigen-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// petstoreSpec is a small v3 spec shared by the end-to-end tests.
+const petstoreSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Pet Store", "version": "1"},
+	"paths": {
+		"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok"}}}}
+	},
+	"components": {"schemas": {"Pet": {"type": "object"}}}
+}`
+
+// parseTestSpec parses the raw v3 spec, or v2 spec when v2 is set, the same
+// way -spec does.
+func parseTestSpec(t *testing.T, raw string, v2 bool) *openapi3.T {
+	t.Helper()
+	defer func(old bool) { *isOpenAPIV2 = old }(*isOpenAPIV2)
+	*isOpenAPIV2 = v2
+	swagger, _, err := parseSpec([]byte(raw))
+	if err != nil {
+		t.Fatalf("cannot parse spec: %v", err)
+	}
+	return swagger
+}
+
+// testSchema returns the named component schema of swagger.
+func testSchema(t *testing.T, swagger *openapi3.T, name string) *openapi3.SchemaRef {
+	t.Helper()
+	ref, ok := swagger.Components.Schemas[name]
+	if !ok {
+		t.Fatalf("schema %s not found", name)
+	}
+	return ref
+}
+
+func TestProfileFlushedOnExit(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"old.json":        petstoreSpec,
+		"new.json":        strings.Replace(petstoreSpec, "/pets", "/animals", 1),
+		"spec.json":       petstoreSpec,
+		"tpl/out.txt.tpl": "{{ .Info.Title }}",
+	})
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"success", []string{"-spec", "spec.json", "-template", "tpl", "-output", "out"}, 0},
+		{"diff", []string{"-diff", "old.json", "new.json"}, 1},
+		{"fatal", []string{"-spec", "missing.json", "-template", "tpl"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := filepath.Join(dir, tt.name+".pprof")
+			trace := filepath.Join(dir, tt.name+".trace")
+			args := append([]string{"-cpuprofile", profile, "-trace", trace}, tt.args...)
+			out, code := runOpenapigen(t, dir, nil, args...)
+			if code != tt.wantCode {
+				t.Fatalf("exit status = %d, want %d: %s", code, tt.wantCode, out)
+			}
+			for _, fn := range []string{profile, trace} {
+				fi, err := os.Stat(fn)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fi.Size() == 0 {
+					t.Errorf("%s is empty", filepath.Base(fn))
+				}
+			}
+		})
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	extRemap, err := parseExtMap(".go.tpl=.ts, .tpl=.txt, .pb.go.tpl=.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		base     string
+		extRemap map[string]string
+		want     string
+	}{
+		{"models.go.tpl", nil, "models.go"},
+		{"models.go.tpl", extRemap, "models.ts"},
+		{"api.pb.go.tpl", extRemap, "api.proto"},
+		{"README.tpl", extRemap, "README.txt"},
+	}
+	for _, tt := range tests {
+		if got := outputName(tt.base, tt.extRemap); got != tt.want {
+			t.Errorf("outputName(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+	if _, err := parseExtMap(".go=.ts"); err == nil {
+		t.Error("parseExtMap should reject suffixes not ending in .tpl")
+	}
+}
+
+func TestRunPostCmd(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	if err := runPostCmd(dir, "true"); err != nil {
+		t.Errorf("runPostCmd(true) failed: %v", err)
+	}
+	if err := runPostCmd(dir, "touch ran"); err != nil {
+		t.Fatalf("runPostCmd(touch ran) failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err != nil {
+		t.Errorf("post command did not run in the output directory: %v", err)
+	}
+	err := runPostCmd(dir, "echo broken >&2; false")
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("runPostCmd(false) = %v, want an error carrying its stderr", err)
+	}
+}
+
+func TestTemplateEngineBySuffix(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":          strings.Replace(petstoreSpec, "Pet Store", "Pets <b>&</b>", 1),
+		"tpl/index.html.tpl": "<h1>{{ .Info.Title }}</h1>",
+		"tpl/title.go.tpl":   "const title = `{{ .Info.Title }}`",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	if got, want := readTestFile(t, filepath.Join(dir, "out", "index.html")), "<h1>Pets &lt;b&gt;&amp;&lt;/b&gt;</h1>"; got != want {
+		t.Errorf("index.html = %q, want %q", got, want)
+	}
+	if got, want := readTestFile(t, filepath.Join(dir, "out", "title.go")), "const title = `Pets <b>&</b>`"; got != want {
+		t.Errorf("title.go = %q, want %q", got, want)
+	}
+}
+
+func TestWalkZipTemplates(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "templates.zip")
+	fd, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(fd)
+	for _, f := range []struct{ name, content string }{
+		{"sub/models.go.tpl", "package sub"},
+		{"README.md", "not a template"},
+		{"main.go.tpl", "package main"},
+	} {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fd.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err = walkZipTemplates(archive, func(name, tplRaw string) error {
+		got = append(got, name+"="+tplRaw)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"main.go.tpl=package main", "sub/models.go.tpl=package sub"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("walkZipTemplates visited %v, want %v", got, want)
+	}
+}
+
+func TestSkipEmpty(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/blank.txt.tpl": "{{ if false }}nothing{{ end }}\n\t\n",
+		"tpl/skip.txt.tpl":  "{{ skip }}content",
+		"tpl/full.txt.tpl":  "{{ .Info.Title }}",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out", "-skip-empty"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	for _, fn := range []string{"blank.txt", "skip.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, "out", fn)); !os.IsNotExist(err) {
+			t.Errorf("%s should not be created: %v", fn, err)
+		}
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "full.txt")); got != "Pet Store" {
+		t.Errorf("full.txt = %q, want Pet Store", got)
+	}
+}
+
+func TestStagingInsideOutput(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	work := filepath.Join(dir, "work")
+	writeTestFiles(t, work, map[string]string{
+		"spec.json":          petstoreSpec,
+		"tpl/title.txt.tpl":  "{{ .Info.Title }}",
+		"bad/title.txt.tpl":  "new title",
+		"bad/broken.txt.tpl": "{{ .Missing.Field }}",
+	})
+	assertNoStaging := func() {
+		t.Helper()
+		for _, d := range []string{dir, work} {
+			entries, err := ioutil.ReadDir(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if strings.HasPrefix(entry.Name(), ".openapigen") {
+					t.Errorf("staging directory %s left behind", filepath.Join(d, entry.Name()))
+				}
+			}
+		}
+	}
+	if out, code := runOpenapigen(t, work, nil, "-spec", "spec.json", "-template", "tpl"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	assertNoStaging()
+	if got := readTestFile(t, filepath.Join(work, "title.txt")); got != "Pet Store" {
+		t.Errorf("title.txt = %q, want Pet Store", got)
+	}
+	if out, code := runOpenapigen(t, work, nil, "-spec", "spec.json", "-template", "bad"); code == 0 {
+		t.Fatalf("openapigen should fail on the broken template: %s", out)
+	}
+	assertNoStaging()
+	if got := readTestFile(t, filepath.Join(work, "title.txt")); got != "Pet Store" {
+		t.Errorf("failed run replaced title.txt with %q", got)
+	}
+}
+
+func TestFailedFirstRunCreatesNoOutput(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":          petstoreSpec,
+		"bad/title.txt.tpl":  "{{ .Info.Title }}",
+		"bad/broken.txt.tpl": "{{ .Missing.Field }}",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "bad", "-output", "gen/api"); code == 0 {
+		t.Fatalf("openapigen should fail on the broken template: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gen")); !os.IsNotExist(err) {
+		t.Errorf("failed first run left the output tree behind: %v", err)
+	}
+}
+
+func TestJoinConcatParts(t *testing.T) {
+	parts := []concatPart{
+		{path: "b/models.go.tpl", content: []byte("b")},
+		{path: "a.go.tpl", content: []byte("a")},
+		{path: "b/api.go.tpl", content: []byte("api")},
+	}
+	if got, want := string(joinConcatParts(parts, `\n---\n`)), "a\n---\napi\n---\nb"; got != want {
+		t.Errorf("joinConcatParts() = %q, want %q", got, want)
+	}
+}
+
+func TestMinifyJSON(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":           petstoreSpec,
+		"tpl/info.json.tpl":   "{\n  \"title\": {{ goString .Info.Title }},\n  \"tags\": [ ]\n}\n",
+		"tpl/info.txt.tpl":    "{ \"kept\": true }",
+		"bad/broken.json.tpl": "{\"title\": ",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out", "-minify-json"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	if got, want := readTestFile(t, filepath.Join(dir, "out", "info.json")), `{"title":"Pet Store","tags":[]}`; got != want {
+		t.Errorf("info.json = %q, want %q", got, want)
+	}
+	if got, want := readTestFile(t, filepath.Join(dir, "out", "info.txt")), `{ "kept": true }`; got != want {
+		t.Errorf("info.txt = %q, want it untouched", got)
+	}
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "bad", "-output", "out", "-minify-json"); code == 0 {
+		t.Errorf("invalid JSON output should fail under -minify-json: %s", out)
+	}
+}
+
+func TestRenderOutputDir(t *testing.T) {
+	swagger := parseTestSpec(t, petstoreSpec, false)
+	got, err := renderOutputDir("{{ .Info.Title | snake }}/v{{ .Info.Version }}", swagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("pet_store", "v1"); got != want {
+		t.Errorf("renderOutputDir() = %q, want %q", got, want)
+	}
+	if _, err := renderOutputDir("../{{ .Info.Title }}", swagger); err == nil {
+		t.Error("renderOutputDir escaping the output directory should fail")
+	}
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/title.txt.tpl": "{{ .Info.Title }}",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "gen/{{ .Info.Title | snake }}"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "gen", "pet_store", "title.txt")); got != "Pet Store" {
+		t.Errorf("gen/pet_store/title.txt = %q, want Pet Store", got)
+	}
+}
+
+func TestOutputCollision(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/api.go.tpl":    "from go",
+		"tpl/api.txt.tpl":   "from txt",
+		"tpl/other.txt.tpl": "other",
+	})
+	args := []string{"-spec", "spec.json", "-template", "tpl", "-ext-map", ".go.tpl=.txt"}
+	out, code := runOpenapigen(t, dir, nil, append(args, "-output", "fail")...)
+	if code == 0 {
+		t.Fatalf("colliding templates were accepted: %s", out)
+	}
+	for _, want := range []string{"api.go.tpl", "api.txt.tpl", "both render into api.txt"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("collision report %q does not mention %q", out, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fail", "other.txt")); !os.IsNotExist(err) {
+		t.Errorf("outputs were written despite the collision: %v", err)
+	}
+
+	out, code = runOpenapigen(t, dir, nil, append(args, "-output", "ordered", "-overwrite-order")...)
+	if code != 0 {
+		t.Fatalf("-overwrite-order run failed: %s", out)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "ordered", "api.txt")); got != "from txt" {
+		t.Errorf("api.txt = %q, want the output of the last template walked", got)
+	}
+}
+
+func TestTemplateErrorsNameTheFile(t *testing.T) {
+	tests := map[string]string{
+		"parse":   "{{ end }}",
+		"execute": `{{ index "pets" 7 }}`,
+	}
+	for name, tpl := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir)
+			writeTestFiles(t, dir, map[string]string{
+				"spec.json":                petstoreSpec,
+				"tpl/models/broken.go.tpl": tpl,
+			})
+			out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out")
+			if code == 0 {
+				t.Fatalf("broken template rendered: %s", out)
+			}
+			if !strings.Contains(out, "models/broken.go.tpl") {
+				t.Errorf("error %q does not name the template file", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifest records the files produced by a run, relative to the output
+// directory, along with the SHA-256 of their contents.
+type manifest struct {
+	Files []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+func (m *manifest) add(path, sum string) {
+	for i, f := range m.Files {
+		if f.Path == path {
+			m.Files[i].SHA256 = sum
+			return
+		}
+	}
+	m.Files = append(m.Files, manifestEntry{Path: path, SHA256: sum})
+}
+
+func (m *manifest) has(path string) bool {
+	for _, f := range m.Files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// readManifest loads a manifest file. A missing file yields an empty
+// manifest.
+func readManifest(fn string) (*manifest, error) {
+	m := &manifest{}
+	b, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest %s: %w", fn, err)
+	}
+	return m, nil
+}
+
+func writeManifest(fn string, m *manifest) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	b, err := json.MarshalIndent(m, "", "	")
+	if err != nil {
+		return fmt.Errorf("cannot encode manifest: %w", err)
+	}
+	return ioutil.WriteFile(fn, append(b, '\n'), 0644)
+}
+
+// pruneStaleFiles removes from outputDir the files listed in prior that are not
+// present in current. Hand-edited files are left in place.
+func pruneStaleFiles(outputDir string, prior, current *manifest) ([]string, error) {
+	var pruned []string
+	for _, f := range prior.Files {
+		fn := filepath.Join(outputDir, filepath.FromSlash(f.Path))
+		if current.has(f.Path) || isEdited(fn) {
+			continue
+		}
+		err := os.Remove(fn)
+		if err != nil && !os.IsNotExist(err) {
+			return pruned, fmt.Errorf("cannot remove stale file %s: %w", f.Path, err)
+		}
+		pruned = append(pruned, f.Path)
+	}
+	return pruned, nil
+}
+
+// cleanManifest removes from outputDir every file listed in m, along with
+// the directories that become empty as a result. Hand-edited files are left
+// in place.
+func cleanManifest(outputDir string, m *manifest) error {
+	for _, f := range m.Files {
+		fn := filepath.Join(outputDir, filepath.FromSlash(f.Path))
+		if isEdited(fn) {
+			continue
+		}
+		if err := os.Remove(fn); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove %s: %w", f.Path, err)
+		}
+		for dir := filepath.Dir(fn); dir != outputDir && strings.HasPrefix(dir, outputDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
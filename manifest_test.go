@@ -0,0 +1,89 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPruneStaleOnSecondRun(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":           petstoreSpec,
+		"tpl/keep.txt.tpl":    "keep",
+		"tpl/sub/gone.go.tpl": "package sub",
+	})
+	args := []string{"-spec", "spec.json", "-template", "tpl", "-output", "out", "-manifest", "manifest.json", "-prune-stale"}
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("first run failed: %s", out)
+	}
+	gone := filepath.Join(dir, "out", "sub", "gone.go")
+	if _, err := os.Stat(gone); err != nil {
+		t.Fatalf("first run did not render sub/gone.go: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "tpl", "sub", "gone.go.tpl")); err != nil {
+		t.Fatal(err)
+	}
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("second run failed: %s", out)
+	}
+	if _, err := os.Stat(gone); !os.IsNotExist(err) {
+		t.Errorf("stale sub/gone.go was not removed: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "keep.txt")); got != "keep" {
+		t.Errorf("keep.txt = %q, want keep", got)
+	}
+	m, err := readManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 1 || m.Files[0].Path != "keep.txt" {
+		t.Errorf("manifest lists %+v, want only keep.txt", m.Files)
+	}
+}
+
+func TestCleanThenRegenerate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":              petstoreSpec,
+		"tpl/title.txt.tpl":      "{{ .Info.Title }}",
+		"tpl/old/models.go.tpl":  "package old",
+		"out/handwritten/doc.go": "package handwritten",
+	})
+	args := []string{"-spec", "spec.json", "-template", "tpl", "-output", "out", "-manifest", "manifest.json", "-clean"}
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("first run failed: %s", out)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "tpl", "old")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, dir, map[string]string{"tpl/title.txt.tpl": "{{ .Info.Version }}"})
+	if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+		t.Fatalf("second run failed: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "old")); !os.IsNotExist(err) {
+		t.Errorf("old/ should be removed along with old/models.go: %v", err)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "title.txt")); got != "1" {
+		t.Errorf("title.txt = %q, want the regenerated 1", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "handwritten", "doc.go")); got != "package handwritten" {
+		t.Errorf("files missing from the manifest must be kept, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestOutputModes(t *testing.T) {
+	defer syscall.Umask(syscall.Umask(022))
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":             petstoreSpec,
+		"tpl/sub/title.txt.tpl": "{{ .Info.Title }}",
+	})
+	tests := []struct {
+		name              string
+		args              []string
+		wantDir, wantFile os.FileMode
+	}{
+		{"explicit", []string{"-dir-mode", "0775", "-file-mode", "0664"}, 0775, 0664},
+		{"restrictive", []string{"-dir-mode", "0750", "-file-mode", "0640"}, 0750, 0640},
+		{"default", nil, 0755, 0644},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, tt.name)
+			args := append([]string{"-spec", "spec.json", "-template", "tpl", "-output", output}, tt.args...)
+			if out, code := runOpenapigen(t, dir, nil, args...); code != 0 {
+				t.Fatalf("openapigen failed: %s", out)
+			}
+			for _, c := range []struct {
+				fn   string
+				want os.FileMode
+			}{
+				{output, tt.wantDir},
+				{filepath.Join(output, "sub"), tt.wantDir},
+				{filepath.Join(output, "sub", "title.txt"), tt.wantFile},
+			} {
+				fi, err := os.Stat(c.fn)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := fi.Mode().Perm(); got != c.want {
+					t.Errorf("mode of %s = %#o, want %#o", c.fn, got, c.want)
+				}
+			}
+		})
+	}
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-file-mode", "0999"); code == 0 {
+		t.Errorf("invalid -file-mode should be rejected: %s", out)
+	}
+}
@@ -0,0 +1,755 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// httpMethods lists the HTTP methods an OpenAPI path item can declare, in
+// the same order net/http declares its Method constants.
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// httpMethodConst renders the net/http constant name for the given method,
+// e.g. "GET" becomes "http.MethodGet".
+func httpMethodConst(method string) (string, error) {
+	for _, m := range httpMethods {
+		if strings.EqualFold(m, method) {
+			return "http.Method" + m[:1] + strings.ToLower(m[1:]), nil
+		}
+	}
+	return "", fmt.Errorf("unknown HTTP method %q", method)
+}
+
+// isSafe reports whether the HTTP method is safe, that is, read-only: GET,
+// HEAD or OPTIONS.
+func isSafe(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isIdempotent reports whether repeating a request with the HTTP method has
+// the same effect as making it once: the safe methods, PUT and DELETE.
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return isSafe(method)
+}
+
+// allMethods returns the methods declared by the path item, in canonical
+// order.
+func allMethods(pathItem *openapi3.PathItem) []string {
+	methods := []string{}
+	if pathItem == nil {
+		return methods
+	}
+	for _, m := range httpMethods {
+		if pathItem.GetOperation(m) != nil {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// requestBody returns the request body of the operation, or nil when the
+// operation does not declare one.
+func requestBody(operation *openapi3.Operation) *openapi3.RequestBody {
+	if operation == nil || operation.RequestBody == nil {
+		return nil
+	}
+	return operation.RequestBody.Value
+}
+
+// requestBodyRequired reports whether the operation's request body is
+// mandatory.
+func requestBodyRequired(operation *openapi3.Operation) bool {
+	body := requestBody(operation)
+	return body != nil && body.Required
+}
+
+// requestBodyDescription returns the description of the operation's request
+// body.
+func requestBodyDescription(operation *openapi3.Operation) string {
+	body := requestBody(operation)
+	if body == nil {
+		return ""
+	}
+	return body.Description
+}
+
+// jsonMediaSchema returns the schema of the JSON media type in content,
+// preferring application/json over other JSON-flavoured media types.
+func jsonMediaSchema(content openapi3.Content) *openapi3.SchemaRef {
+	if mt := content.Get("application/json"); mt != nil {
+		return mt.Schema
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "/json") {
+			return content[mediaType].Schema
+		}
+	}
+	return nil
+}
+
+// mediaSchema returns the schema of the given media type in content, or of
+// the JSON media type when mediaType is empty.
+func mediaSchema(content openapi3.Content, mediaType string) *openapi3.SchemaRef {
+	if mediaType == "" {
+		return jsonMediaSchema(content)
+	}
+	if mt := content.Get(mediaType); mt != nil {
+		return mt.Schema
+	}
+	return nil
+}
+
+// contentOf returns the content of a request body or response.
+func contentOf(v interface{}) openapi3.Content {
+	switch v := v.(type) {
+	case openapi3.Content:
+		return v
+	case *openapi3.RequestBody:
+		if v != nil {
+			return v.Content
+		}
+	case *openapi3.RequestBodyRef:
+		if v != nil && v.Value != nil {
+			return v.Value.Content
+		}
+	case *openapi3.Response:
+		if v != nil {
+			return v.Content
+		}
+	case *openapi3.ResponseRef:
+		if v != nil && v.Value != nil {
+			return v.Value.Content
+		}
+	}
+	return nil
+}
+
+// anyContentSchema returns the JSON schema of the content of a request body
+// or response, or, when there is no JSON media type, the schema of the first
+// media type in alphabetical order.
+func anyContentSchema(v interface{}) *openapi3.SchemaRef {
+	content := contentOf(v)
+	if schema := jsonMediaSchema(content); schema != nil {
+		return schema
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if mt := content[mediaType]; mt != nil && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// requestBodySchema returns the schema of the operation's request body for
+// the given media type, or for its JSON media type when none is given.
+func requestBodySchema(operation *openapi3.Operation, mediaType ...string) *openapi3.SchemaRef {
+	body := requestBody(operation)
+	if body == nil {
+		return nil
+	}
+	if len(mediaType) > 0 {
+		return mediaSchema(body.Content, mediaType[0])
+	}
+	return jsonMediaSchema(body.Content)
+}
+
+// responseSchema returns the schema of the operation's response with the
+// given status code for the given media type, or for its JSON media type
+// when none is given.
+func responseSchema(operation *openapi3.Operation, code string, mediaType ...string) *openapi3.SchemaRef {
+	if operation == nil {
+		return nil
+	}
+	resp := operation.Responses[code]
+	if resp == nil || resp.Value == nil {
+		return nil
+	}
+	if len(mediaType) > 0 {
+		return mediaSchema(resp.Value.Content, mediaType[0])
+	}
+	return jsonMediaSchema(resp.Value.Content)
+}
+
+// successResponseSchema returns the JSON schema of the first 2xx response of
+// the operation, falling back to the default response.
+func successResponseSchema(operation *openapi3.Operation) *openapi3.SchemaRef {
+	if operation == nil {
+		return nil
+	}
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	codes = append(codes, "default")
+	for _, code := range codes {
+		resp := operation.Responses[code]
+		if resp == nil || resp.Value == nil {
+			continue
+		}
+		if schema := jsonMediaSchema(resp.Value.Content); schema != nil {
+			return schema
+		}
+	}
+	return nil
+}
+
+// responsesWhere returns either the error or the success responses of the
+// operation. The default response is an error when the operation declares
+// any 2xx response, and a success otherwise.
+func responsesWhere(operation *openapi3.Operation, isError bool) map[string]*openapi3.ResponseRef {
+	responses := make(map[string]*openapi3.ResponseRef)
+	if operation == nil {
+		return responses
+	}
+	hasSuccess := false
+	for code := range operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			hasSuccess = true
+		}
+	}
+	for code, resp := range operation.Responses {
+		var codeIsError bool
+		switch {
+		case code == "default":
+			codeIsError = hasSuccess
+		case strings.HasPrefix(code, "2"):
+			codeIsError = false
+		case strings.HasPrefix(code, "4"), strings.HasPrefix(code, "5"):
+			codeIsError = true
+		default:
+			continue
+		}
+		if codeIsError == isError {
+			responses[code] = resp
+		}
+	}
+	return responses
+}
+
+// errorResponses returns the 4xx and 5xx responses of the operation, keyed by
+// status code.
+func errorResponses(operation *openapi3.Operation) map[string]*openapi3.ResponseRef {
+	return responsesWhere(operation, true)
+}
+
+// successResponses returns the 2xx responses of the operation, keyed by
+// status code.
+func successResponses(operation *openapi3.Operation) map[string]*openapi3.ResponseRef {
+	return responsesWhere(operation, false)
+}
+
+// successSchemas returns the JSON schemas of the 2xx responses of the
+// operation, keyed by status code. Responses without a JSON body are left
+// out.
+func successSchemas(operation *openapi3.Operation) map[string]*openapi3.SchemaRef {
+	schemas := make(map[string]*openapi3.SchemaRef)
+	if operation == nil {
+		return schemas
+	}
+	for code, resp := range operation.Responses {
+		if !strings.HasPrefix(code, "2") || resp == nil || resp.Value == nil {
+			continue
+		}
+		if schema := jsonMediaSchema(resp.Value.Content); schema != nil {
+			schemas[code] = schema
+		}
+	}
+	return schemas
+}
+
+// singleSuccessSchema returns the JSON schema shared by the 2xx responses of
+// the operation, or nil when none declares one. It fails when they declare
+// different schemas.
+func singleSuccessSchema(operation *openapi3.Operation) (*openapi3.SchemaRef, error) {
+	schemas := successSchemas(operation)
+	codes := make([]string, 0, len(schemas))
+	for code := range schemas {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	var single *openapi3.SchemaRef
+	for _, code := range codes {
+		schema := schemas[code]
+		switch {
+		case single == nil:
+			single = schema
+		case single == schema, single.Ref != "" && single.Ref == schema.Ref:
+		default:
+			return nil, fmt.Errorf("operation %s declares different success schemas for %s", operation.OperationID, strings.Join(codes, ", "))
+		}
+	}
+	return single, nil
+}
+
+// defaultResponse returns the default response of the operation, or nil.
+func defaultResponse(operation *openapi3.Operation) *openapi3.ResponseRef {
+	if operation == nil {
+		return nil
+	}
+	return operation.Responses.Default()
+}
+
+// defaultResponseSchema returns the schema of the operation's default
+// response for the given media type, or for its JSON media type when none is
+// given.
+func defaultResponseSchema(operation *openapi3.Operation, mediaType ...string) *openapi3.SchemaRef {
+	return responseSchema(operation, "default", mediaType...)
+}
+
+// returnsArray reports whether the successful response of the operation is a
+// JSON array.
+func returnsArray(operation *openapi3.Operation) bool {
+	schema := successResponseSchema(operation)
+	return schema != nil && schema.Value != nil && schema.Value.Type == "array"
+}
+
+// returnsArrayItem returns the element schema of the operation's array
+// response, or nil when the operation does not return an array.
+func returnsArrayItem(operation *openapi3.Operation) *openapi3.SchemaRef {
+	if !returnsArray(operation) {
+		return nil
+	}
+	return successResponseSchema(operation).Value.Items
+}
+
+// pathItemOf finds the path item that declares the given operation.
+func pathItemOf(swagger *openapi3.T, operation *openapi3.Operation) *openapi3.PathItem {
+	if swagger == nil || operation == nil {
+		return nil
+	}
+	for _, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, op := range pathItem.Operations() {
+			if op == operation {
+				return pathItem
+			}
+		}
+	}
+	return nil
+}
+
+// operationParameters returns the effective parameters of the operation:
+// those declared on its path item, overridden by those declared on the
+// operation itself.
+func operationParameters(swagger *openapi3.T, operation *openapi3.Operation) openapi3.Parameters {
+	if operation == nil {
+		return nil
+	}
+	var params openapi3.Parameters
+	if pathItem := pathItemOf(swagger, operation); pathItem != nil {
+		for _, param := range pathItem.Parameters {
+			if param == nil || param.Value == nil {
+				continue
+			}
+			if operation.Parameters.GetByInAndName(param.Value.In, param.Value.Name) == nil {
+				params = append(params, param)
+			}
+		}
+	}
+	return append(params, operation.Parameters...)
+}
+
+// hasParamsIn reports whether the operation has any effective parameter
+// located in the given place (path, query, header or cookie).
+func hasParamsIn(swagger *openapi3.T, operation *openapi3.Operation, in string) bool {
+	for _, param := range operationParameters(swagger, operation) {
+		if param != nil && param.Value != nil && param.Value.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBody reports whether the operation declares a request body.
+func hasBody(operation *openapi3.Operation) bool {
+	return requestBody(operation) != nil
+}
+
+// isBinarySchema reports whether the schema is a binary string, or an array
+// of binary strings.
+func isBinarySchema(schema *openapi3.SchemaRef) bool {
+	if schema == nil || schema.Value == nil {
+		return false
+	}
+	if schema.Value.Type == "array" {
+		return isBinarySchema(schema.Value.Items)
+	}
+	return schema.Value.Type == "string" && schema.Value.Format == "binary"
+}
+
+// isBinaryResponse reports whether the operation's response with the given
+// status code is a binary download: an application/octet-stream body or a
+// body whose schema is a binary string.
+func isBinaryResponse(operation *openapi3.Operation, code string) bool {
+	if operation == nil {
+		return false
+	}
+	resp := operation.Responses[code]
+	if resp == nil || resp.Value == nil {
+		return false
+	}
+	for mediaType, mt := range resp.Value.Content {
+		if mediaType == "application/octet-stream" {
+			return true
+		}
+		if mt != nil && mt.Schema != nil && mt.Schema.Value != nil &&
+			mt.Schema.Value.Type == "string" && mt.Schema.Value.Format == "binary" {
+			return true
+		}
+	}
+	return false
+}
+
+// fileUploadFields lists, sorted, the request body properties that carry
+// binary payloads.
+func fileUploadFields(operation *openapi3.Operation) []string {
+	fields := []string{}
+	body := requestBody(operation)
+	if body == nil {
+		return fields
+	}
+	seen := make(map[string]bool)
+	for _, mt := range body.Content {
+		if mt == nil || mt.Schema == nil || mt.Schema.Value == nil {
+			continue
+		}
+		for name, prop := range mt.Schema.Value.Properties {
+			if isBinarySchema(prop) && !seen[name] {
+				seen[name] = true
+				fields = append(fields, name)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// isFileUpload reports whether the operation uploads files, either because
+// it accepts multipart/form-data or because its body has binary properties.
+func isFileUpload(operation *openapi3.Operation) bool {
+	body := requestBody(operation)
+	if body == nil {
+		return false
+	}
+	if body.Content.Get("multipart/form-data") != nil {
+		return true
+	}
+	return len(fileUploadFields(operation)) > 0
+}
+
+// hasNoContent reports whether none of the operation's success responses
+// carry a body, as is the case for 204 No Content.
+func hasNoContent(operation *openapi3.Operation) bool {
+	if operation == nil {
+		return false
+	}
+	found := false
+	for code, resp := range operation.Responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		found = true
+		if code == "204" || resp == nil || resp.Value == nil {
+			continue
+		}
+		if len(resp.Value.Content) > 0 {
+			return false
+		}
+	}
+	return found
+}
+
+// securityRequirement is a security scheme required by an operation, along
+// with the scopes it must grant.
+type securityRequirement struct {
+	SchemeName string
+	Scopes     []string
+}
+
+// operationSecurityRequirements returns the effective security requirements
+// of the operation (its own, or else the spec's global ones). Each group is
+// one alternative: satisfying every scheme of any single group suffices. An
+// empty group, from the empty requirement ({}), makes authentication
+// optional. Scheme names are sorted within each group.
+func operationSecurityRequirements(swagger *openapi3.T, operation *openapi3.Operation) [][]securityRequirement {
+	groups := [][]securityRequirement{}
+	if operation == nil {
+		return groups
+	}
+	var security openapi3.SecurityRequirements
+	if operation.Security != nil {
+		security = *operation.Security
+	} else if swagger != nil {
+		security = swagger.Security
+	}
+	for _, requirement := range security {
+		names := make([]string, 0, len(requirement))
+		for name := range requirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		group := []securityRequirement{}
+		for _, name := range names {
+			group = append(group, securityRequirement{
+				SchemeName: name,
+				Scopes:     append([]string{}, requirement[name]...),
+			})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// namedCallback pairs a callback with the name it is declared under.
+type namedCallback struct {
+	Name     string
+	Callback *openapi3.Callback
+}
+
+// operationCallbacks lists the callbacks of the operation sorted by name.
+func operationCallbacks(operation *openapi3.Operation) []namedCallback {
+	callbacks := []namedCallback{}
+	if operation == nil {
+		return callbacks
+	}
+	for name, ref := range operation.Callbacks {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		callbacks = append(callbacks, namedCallback{Name: name, Callback: ref.Value})
+	}
+	sort.Slice(callbacks, func(i, j int) bool { return callbacks[i].Name < callbacks[j].Name })
+	return callbacks
+}
+
+// namedLink pairs a response link with the name it is declared under.
+type namedLink struct {
+	Name string
+	Link *openapi3.Link
+}
+
+// responseLinks lists, sorted by name, the links of the operation's response
+// for the given status code.
+func responseLinks(operation *openapi3.Operation, statusCode string) []namedLink {
+	links := []namedLink{}
+	if operation == nil {
+		return links
+	}
+	resp := operation.Responses[statusCode]
+	if resp == nil || resp.Value == nil {
+		return links
+	}
+	for name, ref := range resp.Value.Links {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		links = append(links, namedLink{Name: name, Link: ref.Value})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Name < links[j].Name })
+	return links
+}
+
+// usesOnlyJSON reports whether every request and response body in the spec
+// is declared exclusively as application/json.
+func usesOnlyJSON(swagger *openapi3.T) bool {
+	onlyJSON := func(content openapi3.Content) bool {
+		for mediaType := range content {
+			if mediaType != "application/json" {
+				return false
+			}
+		}
+		return true
+	}
+	if swagger == nil {
+		return true
+	}
+	for _, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, operation := range pathItem.Operations() {
+			if body := requestBody(operation); body != nil && !onlyJSON(body.Content) {
+				return false
+			}
+			for _, resp := range operation.Responses {
+				if resp != nil && resp.Value != nil && !onlyJSON(resp.Value.Content) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// operationConsumes returns, sorted, the media types the operation accepts
+// as request body, as a v2 consumes list would declare them.
+func operationConsumes(operation *openapi3.Operation) []string {
+	mediaTypes := []string{}
+	if body := requestBody(operation); body != nil {
+		for mediaType := range body.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// operationProduces returns, sorted, the media types of all the responses of
+// the operation, as a v2 produces list would declare them.
+func operationProduces(operation *openapi3.Operation) []string {
+	mediaTypes := []string{}
+	if operation == nil {
+		return mediaTypes
+	}
+	seen := make(map[string]bool)
+	for _, resp := range operation.Responses {
+		if resp == nil || resp.Value == nil {
+			continue
+		}
+		for mediaType := range resp.Value.Content {
+			if !seen[mediaType] {
+				seen[mediaType] = true
+				mediaTypes = append(mediaTypes, mediaType)
+			}
+		}
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// operationsWithoutID returns, as "METHOD /path" and sorted, the operations
+// of the spec that lack an operationId.
+func operationsWithoutID(swagger *openapi3.T) []string {
+	var missing []string
+	if swagger == nil {
+		return missing
+	}
+	for path, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			if op := pathItem.GetOperation(method); op != nil && op.OperationID == "" {
+				missing = append(missing, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// synthesizeOperationIDs assigns an operationId derived from the method and
+// path, such as getPetsId for GET /pets/{id}, to every operation lacking one.
+// Clashes with existing identifiers are resolved with a numeric suffix.
+func synthesizeOperationIDs(swagger *openapi3.T) {
+	if swagger == nil {
+		return
+	}
+	used := make(map[string]bool)
+	for _, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, op := range pathItem.Operations() {
+			used[op.OperationID] = true
+		}
+	}
+	paths := make([]string, 0, len(swagger.Paths))
+	for path := range swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := swagger.Paths[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			op := pathItem.GetOperation(method)
+			if op == nil || op.OperationID != "" {
+				continue
+			}
+			base := toLowerCamel(strings.ToLower(method) + "_" + pathToFilename(path))
+			id := base
+			for i := 2; used[id]; i++ {
+				id = fmt.Sprintf("%s%d", base, i)
+			}
+			used[id] = true
+			op.OperationID = id
+		}
+	}
+}
+
+// operationHash returns the hex-encoded SHA-256 of the canonical JSON form
+// of the operation. Referenced components contribute only their $ref, so
+// the hash changes only when the operation itself does.
+func operationHash(operation *openapi3.Operation) (string, error) {
+	raw, err := json.Marshal(operation)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode operation: %w", err)
+	}
+	var canonical interface{}
+	if err := json.Unmarshal(raw, &canonical); err != nil {
+		return "", fmt.Errorf("cannot decode operation: %w", err)
+	}
+	raw, err = json.Marshal(canonical)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode operation: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,681 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// testOperation returns the operation of swagger at the given method and
+// path.
+func testOperation(t *testing.T, swagger *openapi3.T, method, path string) *openapi3.Operation {
+	t.Helper()
+	pathItem := swagger.Paths.Find(path)
+	if pathItem == nil {
+		t.Fatalf("path %s not found", path)
+	}
+	op := pathItem.GetOperation(method)
+	if op == nil {
+		t.Fatalf("operation %s %s not found", method, path)
+	}
+	return op
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "bodies", "version": "1"},
+		"paths": {"/pets": {
+			"post": {
+				"requestBody": {"required": true, "content": {"application/json": {"schema": {"type": "object"}}}},
+				"responses": {"201": {"description": "created"}}
+			},
+			"put": {
+				"requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}},
+				"responses": {"200": {"description": "ok"}}
+			},
+			"get": {"responses": {"200": {"description": "ok"}}}
+		}}
+	}`, false)
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"POST", true},
+		{"PUT", false},
+		{"GET", false},
+	}
+	for _, tt := range tests {
+		if got := requestBodyRequired(testOperation(t, swagger, tt.method, "/pets")); got != tt.want {
+			t.Errorf("requestBodyRequired(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPMethodConst(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"GET", "http.MethodGet"},
+		{"HEAD", "http.MethodHead"},
+		{"POST", "http.MethodPost"},
+		{"PUT", "http.MethodPut"},
+		{"PATCH", "http.MethodPatch"},
+		{"DELETE", "http.MethodDelete"},
+		{"CONNECT", "http.MethodConnect"},
+		{"OPTIONS", "http.MethodOptions"},
+		{"TRACE", "http.MethodTrace"},
+		{"get", "http.MethodGet"},
+	}
+	for _, tt := range tests {
+		got, err := httpMethodConst(tt.method)
+		if err != nil {
+			t.Errorf("httpMethodConst(%q) failed: %v", tt.method, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("httpMethodConst(%q) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+	if _, err := httpMethodConst("FETCH"); err == nil {
+		t.Error("httpMethodConst(FETCH) should fail")
+	}
+}
+
+func TestAllMethods(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "methods", "version": "1"},
+		"paths": {"/pets/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+			"delete": {"responses": {"204": {"description": "deleted"}}},
+			"get": {"responses": {"200": {"description": "ok"}}},
+			"patch": {"responses": {"200": {"description": "ok"}}}
+		}}
+	}`, false)
+	got := allMethods(swagger.Paths.Find("/pets/{id}"))
+	want := []string{"GET", "PATCH", "DELETE"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("allMethods() = %v, want %v", got, want)
+	}
+	if got := allMethods(nil); len(got) != 0 {
+		t.Errorf("allMethods(nil) = %v, want none", got)
+	}
+}
+
+func TestReturnsArray(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "lists", "version": "1"},
+		"paths": {
+			"/pets": {"get": {"responses": {"200": {"description": "ok", "content": {"application/json": {
+				"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}
+			}}}}}},
+			"/pets/{id}": {"get": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+				"responses": {"200": {"description": "ok", "content": {"application/json": {
+					"schema": {"$ref": "#/components/schemas/Pet"}
+				}}}}
+			}}
+		},
+		"components": {"schemas": {"Pet": {"type": "object"}}}
+	}`, false)
+	list := testOperation(t, swagger, "GET", "/pets")
+	if !returnsArray(list) {
+		t.Error("returnsArray(GET /pets) = false, want true")
+	}
+	if item := returnsArrayItem(list); item == nil || item.Ref != "#/components/schemas/Pet" {
+		t.Errorf("returnsArrayItem(GET /pets) = %+v, want a reference to Pet", item)
+	}
+	single := testOperation(t, swagger, "GET", "/pets/{id}")
+	if returnsArray(single) {
+		t.Error("returnsArray(GET /pets/{id}) = true, want false")
+	}
+	if item := returnsArrayItem(single); item != nil {
+		t.Errorf("returnsArrayItem(GET /pets/{id}) = %+v, want nil", item)
+	}
+}
+
+func TestHasParamsIn(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "params", "version": "1"},
+		"paths": {
+			"/pets/{id}": {
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+				"get": {"responses": {"200": {"description": "ok"}}}
+			},
+			"/pets": {"post": {
+				"parameters": [{"name": "dryRun", "in": "query", "schema": {"type": "boolean"}}],
+				"requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}},
+				"responses": {"201": {"description": "created"}}
+			}}
+		}
+	}`, false)
+	tests := []struct {
+		method, path                     string
+		wantPath, wantQuery, wantHeaders bool
+		wantBody                         bool
+	}{
+		{"GET", "/pets/{id}", true, false, false, false},
+		{"POST", "/pets", false, true, false, true},
+	}
+	for _, tt := range tests {
+		op := testOperation(t, swagger, tt.method, tt.path)
+		if got := hasParamsIn(swagger, op, openapi3.ParameterInPath); got != tt.wantPath {
+			t.Errorf("%s %s: hasPathParams = %v, want %v", tt.method, tt.path, got, tt.wantPath)
+		}
+		if got := hasParamsIn(swagger, op, openapi3.ParameterInQuery); got != tt.wantQuery {
+			t.Errorf("%s %s: hasQuery = %v, want %v", tt.method, tt.path, got, tt.wantQuery)
+		}
+		if got := hasParamsIn(swagger, op, openapi3.ParameterInHeader); got != tt.wantHeaders {
+			t.Errorf("%s %s: hasHeaders = %v, want %v", tt.method, tt.path, got, tt.wantHeaders)
+		}
+		if got := hasBody(op); got != tt.wantBody {
+			t.Errorf("%s %s: hasBody = %v, want %v", tt.method, tt.path, got, tt.wantBody)
+		}
+	}
+}
+
+func TestIsFileUpload(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "uploads", "version": "1"},
+		"paths": {
+			"/avatars": {"post": {
+				"requestBody": {"content": {"multipart/form-data": {"schema": {
+					"type": "object",
+					"properties": {
+						"file": {"type": "string", "format": "binary"},
+						"attachments": {"type": "array", "items": {"type": "string", "format": "binary"}},
+						"caption": {"type": "string"}
+					}
+				}}}},
+				"responses": {"201": {"description": "created"}}
+			}},
+			"/pets": {"post": {
+				"requestBody": {"content": {"application/json": {"schema": {
+					"type": "object", "properties": {"name": {"type": "string"}}
+				}}}},
+				"responses": {"201": {"description": "created"}}
+			}}
+		}
+	}`, false)
+	upload := testOperation(t, swagger, "POST", "/avatars")
+	if !isFileUpload(upload) {
+		t.Error("isFileUpload(POST /avatars) = false, want true")
+	}
+	if got, want := fileUploadFields(upload), []string{"attachments", "file"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fileUploadFields(POST /avatars) = %v, want %v", got, want)
+	}
+	plain := testOperation(t, swagger, "POST", "/pets")
+	if isFileUpload(plain) {
+		t.Error("isFileUpload(POST /pets) = true, want false")
+	}
+	if got := fileUploadFields(plain); len(got) != 0 {
+		t.Errorf("fileUploadFields(POST /pets) = %v, want none", got)
+	}
+}
+
+func TestHasNoContent(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "no content", "version": "1"},
+		"paths": {"/pets/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+			"delete": {"responses": {"204": {"description": "deleted"}, "404": {"description": "missing"}}},
+			"get": {"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object"}}}}}}
+		}}
+	}`, false)
+	if !hasNoContent(testOperation(t, swagger, "DELETE", "/pets/{id}")) {
+		t.Error("hasNoContent(DELETE) = false, want true")
+	}
+	if hasNoContent(testOperation(t, swagger, "GET", "/pets/{id}")) {
+		t.Error("hasNoContent(GET) = true, want false")
+	}
+}
+
+func TestOperationSecurityRequirements(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "security", "version": "1"},
+		"security": [{"apiKey": []}],
+		"paths": {
+			"/both": {"get": {"security": [{"oauth": ["read"], "apiKey": []}], "responses": {"200": {"description": "ok"}}}},
+			"/either": {"get": {"security": [{"oauth": ["read"]}, {"apiKey": []}], "responses": {"200": {"description": "ok"}}}},
+			"/optional": {"get": {"security": [{}, {"apiKey": []}], "responses": {"200": {"description": "ok"}}}},
+			"/public": {"get": {"security": [], "responses": {"200": {"description": "ok"}}}},
+			"/global": {"get": {"responses": {"200": {"description": "ok"}}}}
+		},
+		"components": {"securitySchemes": {
+			"apiKey": {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			"oauth": {"type": "oauth2", "flows": {"implicit": {"authorizationUrl": "https://example.com/auth", "scopes": {"read": "read"}}}}
+		}}
+	}`, false)
+	apiKey := securityRequirement{SchemeName: "apiKey", Scopes: []string{}}
+	oauth := securityRequirement{SchemeName: "oauth", Scopes: []string{"read"}}
+	tests := []struct {
+		path string
+		want [][]securityRequirement
+	}{
+		{"/both", [][]securityRequirement{{apiKey, oauth}}},
+		{"/either", [][]securityRequirement{{oauth}, {apiKey}}},
+		{"/optional", [][]securityRequirement{{}, {apiKey}}},
+		{"/public", [][]securityRequirement{}},
+		{"/global", [][]securityRequirement{{apiKey}}},
+	}
+	for _, tt := range tests {
+		got := operationSecurityRequirements(swagger, testOperation(t, swagger, "GET", tt.path))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("operationSecurityRequirements(GET %s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOperationCallbacks(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "callbacks", "version": "1"},
+		"paths": {"/subscriptions": {"post": {
+			"responses": {"201": {"description": "subscribed"}},
+			"callbacks": {
+				"onEvent": {"{$request.body#/callbackUrl}": {"post": {"responses": {"200": {"description": "ok"}}}}},
+				"onCancel": {"{$request.body#/cancelUrl}": {"delete": {"responses": {"204": {"description": "ok"}}}}}
+			}
+		}}}
+	}`, false)
+	callbacks := operationCallbacks(testOperation(t, swagger, "POST", "/subscriptions"))
+	if len(callbacks) != 2 || callbacks[0].Name != "onCancel" || callbacks[1].Name != "onEvent" {
+		t.Fatalf("operationCallbacks() = %+v, want onCancel and onEvent", callbacks)
+	}
+	pathItem := (*callbacks[1].Callback)["{$request.body#/callbackUrl}"]
+	if pathItem == nil || pathItem.Post == nil {
+		t.Errorf("onEvent does not declare POST {$request.body#/callbackUrl}")
+	}
+	if got := operationCallbacks(nil); len(got) != 0 {
+		t.Errorf("operationCallbacks(nil) = %v, want none", got)
+	}
+}
+
+func TestResponseLinks(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "links", "version": "1"},
+		"paths": {
+			"/users": {"post": {
+				"operationId": "createUser",
+				"responses": {"201": {
+					"description": "created",
+					"links": {"GetUser": {"operationId": "getUser", "parameters": {"id": "$response.body#/id"}}}
+				}}
+			}},
+			"/users/{id}": {"get": {
+				"operationId": "getUser",
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+				"responses": {"200": {"description": "ok"}}
+			}}
+		}
+	}`, false)
+	op := testOperation(t, swagger, "POST", "/users")
+	links := responseLinks(op, "201")
+	if len(links) != 1 || links[0].Name != "GetUser" {
+		t.Fatalf("responseLinks(201) = %+v, want GetUser", links)
+	}
+	if got := links[0].Link.OperationID; got != "getUser" {
+		t.Errorf("GetUser.OperationID = %q, want getUser", got)
+	}
+	if got := links[0].Link.Parameters["id"]; got != "$response.body#/id" {
+		t.Errorf("GetUser.Parameters[id] = %v, want $response.body#/id", got)
+	}
+	if got := responseLinks(op, "200"); len(got) != 0 {
+		t.Errorf("responseLinks(200) = %v, want none", got)
+	}
+}
+
+func TestUsesOnlyJSON(t *testing.T) {
+	const jsonOnly = `{
+		"openapi": "3.0.0",
+		"info": {"title": "json", "version": "1"},
+		"paths": {"/pets": {"post": {
+			"requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}},
+			"responses": {"201": {"description": "created", "content": {"application/json": {"schema": {"type": "object"}}}}}
+		}}}
+	}`
+	if !usesOnlyJSON(parseTestSpec(t, jsonOnly, false)) {
+		t.Error("usesOnlyJSON(JSON-only spec) = false, want true")
+	}
+	mixed := strings.Replace(jsonOnly, `"created", "content": {"application/json"`, `"created", "content": {"application/xml"`, 1)
+	if usesOnlyJSON(parseTestSpec(t, mixed, false)) {
+		t.Error("usesOnlyJSON(spec with an XML response) = true, want false")
+	}
+}
+
+func TestResponsesWhere(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "responses", "version": "1"},
+		"paths": {
+			"/pets": {"get": {"responses": {
+				"200": {"description": "ok"},
+				"404": {"description": "missing"},
+				"500": {"description": "broken"},
+				"default": {"description": "unexpected"}
+			}}},
+			"/health": {"get": {"responses": {"default": {"description": "ok"}}}}
+		}
+	}`, false)
+	codes := func(responses map[string]*openapi3.ResponseRef) []string {
+		var codes []string
+		for code := range responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return codes
+	}
+	pets := testOperation(t, swagger, "GET", "/pets")
+	if got, want := codes(errorResponses(pets)), []string{"404", "500", "default"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("errorResponses(GET /pets) = %v, want %v", got, want)
+	}
+	if got, want := codes(successResponses(pets)), []string{"200"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("successResponses(GET /pets) = %v, want %v", got, want)
+	}
+	health := testOperation(t, swagger, "GET", "/health")
+	if got := codes(errorResponses(health)); len(got) != 0 {
+		t.Errorf("errorResponses(GET /health) = %v, want none", got)
+	}
+	if got, want := codes(successResponses(health)), []string{"default"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("successResponses(GET /health) = %v, want %v", got, want)
+	}
+}
+
+func TestAnyContentSchema(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "xml", "version": "1"},
+		"paths": {"/pets": {"get": {"responses": {
+			"200": {"description": "ok", "content": {"application/xml": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+			"201": {"description": "ok", "content": {
+				"application/xml": {"schema": {"type": "string"}},
+				"application/vnd.pets+json": {"schema": {"$ref": "#/components/schemas/Pet"}}
+			}}
+		}}}},
+		"components": {"schemas": {"Pet": {"type": "object"}}}
+	}`, false)
+	op := testOperation(t, swagger, "GET", "/pets")
+	xmlOnly := op.Responses["200"]
+	if got := responseSchema(op, "200"); got != nil {
+		t.Errorf("responseSchema(200) = %+v, want nil without a JSON media type", got)
+	}
+	if got := anyContentSchema(xmlOnly); got == nil || got.Ref != "#/components/schemas/Pet" {
+		t.Errorf("anyContentSchema(200) = %+v, want the XML schema", got)
+	}
+	if got := anyContentSchema(op.Responses["201"]); got == nil || got.Ref != "#/components/schemas/Pet" {
+		t.Errorf("anyContentSchema(201) = %+v, want the JSON schema first", got)
+	}
+}
+
+func TestOperationHash(t *testing.T) {
+	const spec = `{
+		"openapi": "3.0.0",
+		"info": {"title": "hashes", "version": "1"},
+		"paths": {
+			"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}}},
+			"/stores": {"get": {"operationId": "listStores", "responses": {"200": {"description": "ok"}}}}
+		},
+		"components": {"schemas": {"Pet": {"type": "object"}}}
+	}`
+	hash := func(raw, path string) string {
+		t.Helper()
+		h, err := operationHash(testOperation(t, parseTestSpec(t, raw, false), "GET", path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	pets, stores := hash(spec, "/pets"), hash(spec, "/stores")
+	if pets != hash(spec, "/pets") {
+		t.Error("operationHash is not stable across loads")
+	}
+	if pets == stores {
+		t.Error("different operations hash the same")
+	}
+	changedStores := strings.Replace(spec, `"listStores", "responses": {"200": {"description": "ok"}}`, `"listStores", "responses": {"200": {"description": "all stores"}}`, 1)
+	if hash(changedStores, "/pets") != pets {
+		t.Error("changing another operation changed the hash")
+	}
+	if hash(changedStores, "/stores") == stores {
+		t.Error("changing the operation did not change its hash")
+	}
+	changedSchema := strings.Replace(spec, `"Pet": {"type": "object"}`, `"Pet": {"type": "object", "description": "a pet"}`, 1)
+	if hash(changedSchema, "/pets") != pets {
+		t.Error("changing a referenced schema changed the hash")
+	}
+}
+
+func TestOperationProducesV2(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"swagger": "2.0",
+		"info": {"title": "v2", "version": "1"},
+		"produces": ["application/json"],
+		"paths": {
+			"/pets": {"get": {
+				"produces": ["application/xml", "application/json"],
+				"consumes": ["application/json"],
+				"responses": {"200": {"description": "ok", "schema": {"type": "array", "items": {"type": "string"}}}}
+			}},
+			"/stores": {"get": {
+				"responses": {"200": {"description": "ok", "schema": {"type": "object"}}}
+			}}
+		}
+	}`, true)
+	if got, want := operationProduces(testOperation(t, swagger, "GET", "/pets")), []string{"application/json", "application/xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("operationProduces(GET /pets) = %v, want %v", got, want)
+	}
+	if got, want := operationProduces(testOperation(t, swagger, "GET", "/stores")), []string{"application/json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("operationProduces(GET /stores) = %v, want the global %v", got, want)
+	}
+}
+
+func TestSynthesizeOperationIDs(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "ids", "version": "1"},
+		"paths": {
+			"/pets/{id}": {
+				"get": {"responses": {"200": {"description": "ok"}}},
+				"delete": {"operationId": "removePet", "responses": {"204": {"description": "ok"}}}
+			},
+			"/pets": {"get": {"operationId": "getPetsID", "responses": {"200": {"description": "ok"}}}}
+		}
+	}`, false)
+	if got, want := operationsWithoutID(swagger), []string{"GET /pets/{id}"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("operationsWithoutID() = %v, want %v", got, want)
+	}
+	synthesizeOperationIDs(swagger)
+	if got := testOperation(t, swagger, "GET", "/pets/{id}").OperationID; got != "getPetsID2" {
+		t.Errorf("synthesized operationId = %q, want getPetsID2 to avoid the existing getPetsID", got)
+	}
+	if got := testOperation(t, swagger, "DELETE", "/pets/{id}").OperationID; got != "removePet" {
+		t.Errorf("existing operationId changed to %q", got)
+	}
+	if got := operationsWithoutID(swagger); len(got) != 0 {
+		t.Errorf("operationsWithoutID() after synthesizing = %v, want none", got)
+	}
+}
+
+func TestOnMissingOperationID(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":       strings.Replace(petstoreSpec, `"operationId": "listPets", `, "", 1),
+		"tpl/ids.txt.tpl": `{{ range $path, $item := .Paths }}{{ $item.Get.OperationID }}{{ end }}`,
+	})
+	tests := []struct {
+		mode     string
+		wantCode int
+		wantLog  string
+		wantIDs  string
+	}{
+		{"fail", 1, "operations without operationId: GET /pets", ""},
+		{"warn", 0, "operation without operationId", ""},
+		{"synthesize", 0, "", "getPets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", tt.mode, "-on-missing-operationid", tt.mode)
+			if code != tt.wantCode {
+				t.Fatalf("exit status = %d, want %d: %s", code, tt.wantCode, out)
+			}
+			if !strings.Contains(out, tt.wantLog) {
+				t.Errorf("output %q does not mention %q", out, tt.wantLog)
+			}
+			if code != 0 {
+				return
+			}
+			if tt.wantLog == "" && strings.Contains(out, "warning") {
+				t.Errorf("unexpected warning: %s", out)
+			}
+			if got := readTestFile(t, filepath.Join(dir, tt.mode, "ids.txt")); got != tt.wantIDs {
+				t.Errorf("ids.txt = %q, want %q", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestDefaultResponse(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "defaults", "version": "1"},
+		"paths": {"/pets": {
+			"get": {"responses": {"default": {"description": "anything", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}}},
+			"post": {"responses": {"201": {"description": "created"}}}
+		}},
+		"components": {"schemas": {"Error": {"type": "object"}}}
+	}`, false)
+	get := testOperation(t, swagger, "GET", "/pets")
+	resp := defaultResponse(get)
+	if resp == nil || resp.Value == nil || *resp.Value.Description != "anything" {
+		t.Fatalf("defaultResponse(GET /pets) = %+v, want the default response", resp)
+	}
+	if schema := defaultResponseSchema(get); schema == nil || schema.Ref != "#/components/schemas/Error" {
+		t.Errorf("defaultResponseSchema(GET /pets) = %+v, want a reference to Error", schema)
+	}
+	post := testOperation(t, swagger, "POST", "/pets")
+	if resp := defaultResponse(post); resp != nil {
+		t.Errorf("defaultResponse(POST /pets) = %+v, want nil", resp)
+	}
+	if schema := defaultResponseSchema(post); schema != nil {
+		t.Errorf("defaultResponseSchema(POST /pets) = %+v, want nil", schema)
+	}
+}
+
+func TestSuccessSchemas(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "success", "version": "1"},
+		"paths": {"/pets": {
+			"put": {"responses": {
+				"200": {"description": "updated", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+				"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+				"204": {"description": "unchanged"},
+				"400": {"description": "bad", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+			}},
+			"post": {"responses": {
+				"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+				"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+			}}
+		}},
+		"components": {"schemas": {"Pet": {"type": "object"}, "Error": {"type": "object"}}}
+	}`, false)
+	put := testOperation(t, swagger, "PUT", "/pets")
+	schemas := successSchemas(put)
+	var codes []string
+	for code, schema := range schemas {
+		codes = append(codes, code)
+		if schema.Ref != "#/components/schemas/Pet" {
+			t.Errorf("successSchemas()[%s] = %s, want Pet", code, schema.Ref)
+		}
+	}
+	sort.Strings(codes)
+	if want := []string{"200", "201"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("successSchemas() codes = %v, want %v", codes, want)
+	}
+	if schema, err := singleSuccessSchema(put); err != nil || schema == nil || schema.Ref != "#/components/schemas/Pet" {
+		t.Errorf("singleSuccessSchema(PUT) = %v, %v, want Pet", schema, err)
+	}
+	if _, err := singleSuccessSchema(testOperation(t, swagger, "POST", "/pets")); err == nil {
+		t.Error("singleSuccessSchema(POST) accepted 200 and 201 with different schemas")
+	}
+}
+
+func TestMethodSemantics(t *testing.T) {
+	tests := []struct {
+		method     string
+		safe       bool
+		idempotent bool
+	}{
+		{"GET", true, true},
+		{"head", true, true},
+		{"OPTIONS", true, true},
+		{"PUT", false, true},
+		{"delete", false, true},
+		{"POST", false, false},
+		{"PATCH", false, false},
+		{"TRACE", false, false},
+	}
+	for _, tt := range tests {
+		if got := isSafe(tt.method); got != tt.safe {
+			t.Errorf("isSafe(%s) = %v, want %v", tt.method, got, tt.safe)
+		}
+		if got := isIdempotent(tt.method); got != tt.idempotent {
+			t.Errorf("isIdempotent(%s) = %v, want %v", tt.method, got, tt.idempotent)
+		}
+	}
+}
+
+func TestIsBinaryResponse(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "downloads", "version": "1"},
+		"paths": {"/files/{id}": {"get": {"responses": {
+			"200": {"description": "file", "content": {"application/octet-stream": {}}},
+			"206": {"description": "part", "content": {"image/png": {"schema": {"type": "string", "format": "binary"}}}},
+			"404": {"description": "missing", "content": {"application/json": {"schema": {"type": "object"}}}}
+		}}}}
+	}`, false)
+	op := testOperation(t, swagger, "GET", "/files/{id}")
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"200", true},
+		{"206", true},
+		{"404", false},
+		{"500", false},
+	}
+	for _, tt := range tests {
+		if got := isBinaryResponse(op, tt.code); got != tt.want {
+			t.Errorf("isBinaryResponse(%s) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
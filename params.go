@@ -0,0 +1,218 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// paramOf normalizes the values templates usually hold when walking
+// parameters (*openapi3.ParameterRef or *openapi3.Parameter) into a
+// *openapi3.Parameter. It returns nil for anything else.
+func paramOf(v interface{}) *openapi3.Parameter {
+	switch p := v.(type) {
+	case *openapi3.ParameterRef:
+		if p == nil {
+			return nil
+		}
+		return p.Value
+	case *openapi3.Parameter:
+		return p
+	}
+	return nil
+}
+
+// paramRequired reports whether the parameter is mandatory. Path parameters
+// are always required.
+func paramRequired(v interface{}) bool {
+	param := paramOf(v)
+	return param != nil && (param.Required || param.In == openapi3.ParameterInPath)
+}
+
+// paramDeprecated reports whether the parameter is marked deprecated.
+func paramDeprecated(v interface{}) bool {
+	param := paramOf(v)
+	return param != nil && param.Deprecated
+}
+
+// paramSchema returns the schema of the parameter, whether declared directly
+// or through its content map. The reference is returned, rather than its
+// value, so goType keeps rendering named types by name.
+func paramSchema(v interface{}) *openapi3.SchemaRef {
+	param := paramOf(v)
+	if param == nil {
+		return nil
+	}
+	if param.Schema != nil {
+		return param.Schema
+	}
+	if schema := jsonMediaSchema(param.Content); schema != nil {
+		return schema
+	}
+	for _, mt := range param.Content {
+		if mt != nil && mt.Schema != nil {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// paramExample returns the example of the parameter: its example, else the
+// first of its examples by name, else the example of its schema.
+func paramExample(v interface{}) interface{} {
+	param := paramOf(v)
+	if param == nil {
+		return nil
+	}
+	if param.Example != nil {
+		return param.Example
+	}
+	names := make([]string, 0, len(param.Examples))
+	for name := range param.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ex := param.Examples[name]; ex != nil && ex.Value != nil && ex.Value.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	if schema := paramSchema(param); schema != nil && schema.Value != nil {
+		return schema.Value.Example
+	}
+	return nil
+}
+
+// isArrayParam reports whether the parameter holds an array.
+func isArrayParam(v interface{}) bool {
+	schema := paramSchema(v)
+	return schema != nil && schema.Value != nil && schema.Value.Type == "array"
+}
+
+// paramCollectionFormat returns how the values of an array parameter are
+// joined, in v2 collectionFormat terms: csv, ssv, tsv, pipes or multi, or
+// deepObject. It is taken from the v2 collectionFormat when the spec was
+// converted, or else derived from the v3 style and explode. Parameters that
+// are not arrays yield an empty string.
+func paramCollectionFormat(v interface{}) string {
+	param := paramOf(v)
+	if param == nil || !isArrayParam(param) {
+		return ""
+	}
+	if format := collectionFormats[param]; format != "" {
+		return format
+	}
+	style := param.Style
+	if style == "" {
+		style = openapi3.SerializationForm
+		if param.In == openapi3.ParameterInPath || param.In == openapi3.ParameterInHeader {
+			style = openapi3.SerializationSimple
+		}
+	}
+	explode := style == openapi3.SerializationForm
+	if param.Explode != nil {
+		explode = *param.Explode
+	}
+	switch style {
+	case openapi3.SerializationForm:
+		if explode {
+			return "multi"
+		}
+		return "csv"
+	case openapi3.SerializationSpaceDelimited:
+		if explode {
+			return "multi"
+		}
+		return "ssv"
+	case openapi3.SerializationPipeDelimited:
+		if explode {
+			return "multi"
+		}
+		return "pipes"
+	case openapi3.SerializationDeepObject:
+		return "deepObject"
+	}
+	return "csv"
+}
+
+// paramsSchema synthesizes an object schema whose properties are the
+// effective parameters of the operation located in the given place.
+func paramsSchema(swagger *openapi3.T, operation *openapi3.Operation, in string) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	for _, ref := range operationParameters(swagger, operation) {
+		param := paramOf(ref)
+		if param == nil || param.In != in {
+			continue
+		}
+		prop := paramSchema(param)
+		if prop == nil {
+			prop = openapi3.NewSchemaRef("", &openapi3.Schema{})
+		}
+		schema.Properties[param.Name] = prop
+		if paramRequired(param) {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	return schema
+}
+
+// parsePaginationParams parses the -pagination-params list: comma-separated
+// groups of query parameter names joined by "+", such as limit+offset.
+func parsePaginationParams(s string) ([][]string, error) {
+	var groups [][]string
+	for _, group := range strings.Split(s, ",") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		var names []string
+		for _, name := range strings.Split(group, "+") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, fmt.Errorf("invalid pagination parameter group %q", group)
+			}
+			names = append(names, name)
+		}
+		groups = append(groups, names)
+	}
+	return groups, nil
+}
+
+// paginationParams returns the query parameters of the operation matching
+// the first group of pagination parameter names it declares in full.
+func paginationParams(swagger *openapi3.T, operation *openapi3.Operation, groups [][]string) []*openapi3.Parameter {
+	query := make(map[string]*openapi3.Parameter)
+	for _, ref := range operationParameters(swagger, operation) {
+		if param := paramOf(ref); param != nil && param.In == openapi3.ParameterInQuery {
+			query[param.Name] = param
+		}
+	}
+	for _, names := range groups {
+		var params []*openapi3.Parameter
+		for _, name := range names {
+			if param, ok := query[name]; ok {
+				params = append(params, param)
+			}
+		}
+		if len(params) == len(names) {
+			return params
+		}
+	}
+	return nil
+}
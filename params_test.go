@@ -0,0 +1,221 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// paramsTestSpec declares one parameter of each kind exercised by the
+// parameter helpers.
+const paramsTestSpec = `{
+	"openapi": "3.0.0",
+	"info": {"title": "params", "version": "1"},
+	"paths": {"/pets/{id}": {
+		"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+		"get": {
+			"parameters": [
+				{"name": "fields", "in": "query", "schema": {"type": "string"}},
+				{"name": "legacy", "in": "query", "deprecated": true, "schema": {"type": "boolean"}},
+				{"name": "X-Trace", "in": "header", "required": true, "schema": {"type": "string"}}
+			],
+			"responses": {"200": {"description": "ok"}}
+		}
+	}}
+}`
+
+// testParam returns the effective parameter of op with the given name.
+func testParam(t *testing.T, swagger *openapi3.T, op *openapi3.Operation, name string) *openapi3.Parameter {
+	t.Helper()
+	for _, ref := range operationParameters(swagger, op) {
+		if param := paramOf(ref); param != nil && param.Name == name {
+			return param
+		}
+	}
+	t.Fatalf("parameter %s not found", name)
+	return nil
+}
+
+func TestParamRequiredAndDeprecated(t *testing.T) {
+	swagger := parseTestSpec(t, paramsTestSpec, false)
+	op := testOperation(t, swagger, "GET", "/pets/{id}")
+	tests := []struct {
+		name           string
+		wantRequired   bool
+		wantDeprecated bool
+	}{
+		{"id", true, false},
+		{"fields", false, false},
+		{"legacy", false, true},
+		{"X-Trace", true, false},
+	}
+	for _, tt := range tests {
+		param := testParam(t, swagger, op, tt.name)
+		if got := paramRequired(param); got != tt.wantRequired {
+			t.Errorf("paramRequired(%s) = %v, want %v", tt.name, got, tt.wantRequired)
+		}
+		if got := paramDeprecated(param); got != tt.wantDeprecated {
+			t.Errorf("paramDeprecated(%s) = %v, want %v", tt.name, got, tt.wantDeprecated)
+		}
+	}
+	if !paramRequired(&openapi3.Parameter{Name: "id", In: openapi3.ParameterInPath}) {
+		t.Error("path parameters must always be required")
+	}
+}
+
+func TestParamSchema(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "param schemas", "version": "1"},
+		"paths": {"/pets": {"get": {
+			"parameters": [
+				{"name": "limit", "in": "query", "schema": {"type": "integer"}},
+				{"name": "filter", "in": "query", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Filter"}}}}
+			],
+			"responses": {"200": {"description": "ok"}}
+		}}},
+		"components": {"schemas": {"Filter": {"type": "object"}}}
+	}`, false)
+	op := testOperation(t, swagger, "GET", "/pets")
+	if schema := paramSchema(testParam(t, swagger, op, "limit")); schema == nil || schema.Value.Type != "integer" {
+		t.Errorf("paramSchema(limit) = %+v, want an integer schema", schema)
+	}
+	schema := paramSchema(testParam(t, swagger, op, "filter"))
+	if schema == nil || schema.Ref != "#/components/schemas/Filter" {
+		t.Fatalf("paramSchema(filter) = %+v, want a reference to Filter", schema)
+	}
+	if got := goType(schema); got != "Filter" {
+		t.Errorf("goType(paramSchema(filter)) = %q, want Filter", got)
+	}
+}
+
+func TestParamsSchema(t *testing.T) {
+	swagger := parseTestSpec(t, paramsTestSpec, false)
+	op := testOperation(t, swagger, "GET", "/pets/{id}")
+	query := paramsSchema(swagger, op, openapi3.ParameterInQuery)
+	if len(query.Properties) != 2 || query.Properties["fields"] == nil || query.Properties["legacy"] == nil {
+		t.Errorf("query params schema has properties %v, want fields and legacy", query.Properties)
+	}
+	if got := query.Properties["legacy"].Value.Type; got != "boolean" {
+		t.Errorf("legacy property type = %q, want boolean", got)
+	}
+	if len(query.Required) != 0 {
+		t.Errorf("query params schema requires %v, want none", query.Required)
+	}
+	header := paramsSchema(swagger, op, openapi3.ParameterInHeader)
+	if len(header.Properties) != 1 || len(header.Required) != 1 || header.Required[0] != "X-Trace" {
+		t.Errorf("header params schema = %+v, want X-Trace required", header)
+	}
+}
+
+func TestPaginationParams(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "pages", "version": "1"},
+		"paths": {
+			"/pets": {"get": {
+				"parameters": [
+					{"name": "offset", "in": "query", "schema": {"type": "integer"}},
+					{"name": "limit", "in": "query", "schema": {"type": "integer"}}
+				],
+				"responses": {"200": {"description": "ok"}}
+			}},
+			"/stores": {"get": {
+				"parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer"}}],
+				"responses": {"200": {"description": "ok"}}
+			}}
+		}
+	}`, false)
+	groups, err := parsePaginationParams("limit+offset, page+per_page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := paginationParams(swagger, testOperation(t, swagger, "GET", "/pets"), groups)
+	if len(params) != 2 || params[0].Name != "limit" || params[1].Name != "offset" {
+		t.Errorf("paginationParams(GET /pets) = %+v, want limit and offset", params)
+	}
+	if params := paginationParams(swagger, testOperation(t, swagger, "GET", "/stores"), groups); params != nil {
+		t.Errorf("paginationParams(GET /stores) = %+v, want nil with offset missing", params)
+	}
+	if _, err := parsePaginationParams("limit+"); err == nil {
+		t.Error("parsePaginationParams should reject empty names")
+	}
+}
+
+func TestParamExample(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "examples", "version": "1"},
+		"paths": {"/pets": {"get": {
+			"parameters": [
+				{"name": "limit", "in": "query", "example": 20, "schema": {"type": "integer", "example": 10}},
+				{"name": "sort", "in": "query", "examples": {"byName": {"value": "name"}, "byAge": {"value": "age"}}, "schema": {"type": "string"}},
+				{"name": "owner", "in": "query", "schema": {"type": "string", "example": "alice"}},
+				{"name": "fields", "in": "query", "schema": {"type": "string"}}
+			],
+			"responses": {"200": {"description": "ok"}}
+		}}}
+	}`, false)
+	op := testOperation(t, swagger, "GET", "/pets")
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{"limit", float64(20)},
+		{"sort", "age"},
+		{"owner", "alice"},
+		{"fields", nil},
+	}
+	for _, tt := range tests {
+		if got := paramExample(testParam(t, swagger, op, tt.name)); got != tt.want {
+			t.Errorf("paramExample(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParamCollectionFormatV2(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"swagger": "2.0",
+		"info": {"title": "formats", "version": "1"},
+		"consumes": ["multipart/form-data"],
+		"paths": {"/pets": {"post": {
+			"operationId": "findPets",
+			"parameters": [
+				{"name": "tags", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "pipes"},
+				{"name": "ids", "in": "query", "type": "array", "items": {"type": "integer"}},
+				{"name": "names", "in": "formData", "type": "array", "items": {"type": "string"}, "collectionFormat": "ssv"}
+			],
+			"responses": {"200": {"description": "ok"}}
+		}}}
+	}`, true)
+	op := testOperation(t, swagger, "POST", "/pets")
+	if got := paramCollectionFormat(testParam(t, swagger, op, "tags")); got != "pipes" {
+		t.Errorf("paramCollectionFormat(tags) = %q, want pipes", got)
+	}
+	if got := paramCollectionFormat(testParam(t, swagger, op, "ids")); got != "multi" {
+		t.Errorf("paramCollectionFormat(ids) = %q, want multi", got)
+	}
+	raw, err := json.Marshal(swagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), collectionFormatExtension) {
+		t.Errorf("%s leaks into the encoded spec: %s", collectionFormatExtension, raw)
+	}
+}
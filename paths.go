@@ -0,0 +1,184 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var pathParamRE = regexp.MustCompile(`\{([^}]+)\}`)
+
+var unsafeFilenameRE = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// routePath converts OpenAPI path templating ({id}) into the syntax expected
+// by the given router. chi and gorilla share OpenAPI's syntax, gin and echo
+// use colon-prefixed parameters. Unknown styles are passed through.
+func routePath(path, style string) string {
+	switch style {
+	case "gin", "echo":
+		return pathParamRE.ReplaceAllString(path, ":$1")
+	}
+	return path
+}
+
+// commonPathPrefix returns the leading path segments shared by every given
+// path, or an empty string when they share none. Arguments may be paths,
+// lists of paths or a Paths object; without arguments, every path of the spec
+// is considered.
+func commonPathPrefix(swagger *openapi3.T, args ...interface{}) (string, error) {
+	var paths []string
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			paths = append(paths, v)
+		case []string:
+			paths = append(paths, v...)
+		case []interface{}:
+			for _, p := range v {
+				s, ok := p.(string)
+				if !ok {
+					return "", fmt.Errorf("commonPathPrefix: unexpected path %T", p)
+				}
+				paths = append(paths, s)
+			}
+		case openapi3.Paths:
+			for p := range v {
+				paths = append(paths, p)
+			}
+		default:
+			return "", fmt.Errorf("commonPathPrefix: unexpected argument %T", arg)
+		}
+	}
+	if len(args) == 0 && swagger != nil {
+		for p := range swagger.Paths {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+	sort.Strings(paths)
+	prefix := strings.Split(strings.Trim(paths[0], "/"), "/")
+	for _, p := range paths[1:] {
+		segments := strings.Split(strings.Trim(p, "/"), "/")
+		n := 0
+		for n < len(prefix) && n < len(segments) && prefix[n] == segments[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(prefix) == 0 || prefix[0] == "" {
+		return "", nil
+	}
+	return "/" + strings.Join(prefix, "/"), nil
+}
+
+// pathToFilename turns an OpenAPI path into a filesystem-safe name: path
+// parameter braces are stripped and segments are joined by the optional
+// separator, "_" by default. The root path maps to "root".
+func pathToFilename(path string, separator ...string) string {
+	sep := "_"
+	if len(separator) > 0 {
+		sep = separator[0]
+	}
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		for _, word := range strings.Split(unsafeFilenameRE.ReplaceAllString(segment, " "), " ") {
+			if word != "" && word != "." && word != ".." {
+				segments = append(segments, word)
+			}
+		}
+	}
+	if len(segments) == 0 {
+		return "root"
+	}
+	return strings.Join(segments, sep)
+}
+
+// trimPathPrefix strips prefix, matched on whole path segments, from every
+// path of the spec. Paths outside the prefix are left unchanged.
+func trimPathPrefix(swagger *openapi3.T, prefix string) error {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if swagger == nil || prefix == "/" {
+		return nil
+	}
+	paths := make(openapi3.Paths, len(swagger.Paths))
+	for path, pathItem := range swagger.Paths {
+		trimmed := path
+		if path == prefix {
+			trimmed = "/"
+		} else if strings.HasPrefix(path, prefix+"/") {
+			trimmed = strings.TrimPrefix(path, prefix)
+		}
+		if _, ok := paths[trimmed]; ok {
+			return fmt.Errorf("trimming %s from the paths makes %s collide with another path", prefix, path)
+		}
+		paths[trimmed] = pathItem
+	}
+	swagger.Paths = paths
+	return nil
+}
+
+// pathOperation is an operation along with the path and method it is
+// declared under.
+type pathOperation struct {
+	Path      string
+	Method    string
+	Operation *openapi3.Operation
+}
+
+// operationsByPathPrefix groups the operations of the spec by the first n
+// segments of their path, n being 1 unless given; a negative n is an error.
+// Within each group, operations are sorted by path and then method.
+func operationsByPathPrefix(swagger *openapi3.T, n ...int) (map[string][]pathOperation, error) {
+	groups := make(map[string][]pathOperation)
+	depth := 1
+	if len(n) > 0 {
+		depth = n[0]
+	}
+	if depth < 0 {
+		return nil, fmt.Errorf("invalid path prefix depth %d", depth)
+	}
+	if swagger == nil {
+		return groups, nil
+	}
+	paths := make([]string, 0, len(swagger.Paths))
+	for path := range swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pathItem := swagger.Paths[path]
+		if pathItem == nil {
+			continue
+		}
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		if len(segments) > depth {
+			segments = segments[:depth]
+		}
+		prefix := "/" + strings.Join(segments, "/")
+		for _, method := range httpMethods {
+			if op := pathItem.GetOperation(method); op != nil {
+				groups[prefix] = append(groups[prefix], pathOperation{Path: path, Method: method, Operation: op})
+			}
+		}
+	}
+	return groups, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRoutePath(t *testing.T) {
+	const path = "/users/{id}/items/{itemId}"
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"chi", "/users/{id}/items/{itemId}"},
+		{"gorilla", "/users/{id}/items/{itemId}"},
+		{"gin", "/users/:id/items/:itemId"},
+		{"echo", "/users/:id/items/:itemId"},
+		{"unknown", "/users/{id}/items/{itemId}"},
+	}
+	for _, tt := range tests {
+		if got := routePath(path, tt.style); got != tt.want {
+			t.Errorf("routePath(%q, %q) = %q, want %q", path, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestCommonPathPrefix(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "prefix", "version": "1"},
+		"paths": {
+			"/v1/pets": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/v1/pets/{id}": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/v1/stores": {"get": {"responses": {"200": {"description": "ok"}}}}
+		}
+	}`, false)
+	tests := []struct {
+		name string
+		args []interface{}
+		want string
+	}{
+		{"spec paths", nil, "/v1"},
+		{"paths object", []interface{}{swagger.Paths}, "/v1"},
+		{"strings", []interface{}{"/v1/pets", "/v1/pets/{id}"}, "/v1/pets"},
+		{"list", []interface{}{[]string{"/v1/pets", "/v2/pets"}}, ""},
+		{"partial segment", []interface{}{"/v1/pets", "/v1/petshop"}, "/v1"},
+	}
+	for _, tt := range tests {
+		got, err := commonPathPrefix(swagger, tt.args...)
+		if err != nil {
+			t.Errorf("%s: commonPathPrefix failed: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: commonPathPrefix() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err := commonPathPrefix(swagger, 1); err == nil {
+		t.Error("commonPathPrefix should reject non-path arguments")
+	}
+}
+
+func TestPathToFilename(t *testing.T) {
+	tests := []struct {
+		path string
+		sep  []string
+		want string
+	}{
+		{"/users/{id}/items/{itemId}", nil, "users_id_items_itemId"},
+		{"/users/{id}/items/{itemId}", []string{"-"}, "users-id-items-itemId"},
+		{"/files/{path*}/../x", nil, "files_path_x"},
+		{"/", nil, "root"},
+	}
+	for _, tt := range tests {
+		if got := pathToFilename(tt.path, tt.sep...); got != tt.want {
+			t.Errorf("pathToFilename(%q, %v) = %q, want %q", tt.path, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestTrimPathPrefix(t *testing.T) {
+	const spec = `{
+		"openapi": "3.0.0",
+		"info": {"title": "prefix", "version": "1"},
+		"paths": {
+			"/api/v1": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/api/v1/pets": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/api/v10/pets": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/health": {"get": {"responses": {"200": {"description": "ok"}}}}
+		}
+	}`
+	swagger := parseTestSpec(t, spec, false)
+	if err := trimPathPrefix(swagger, "/api/v1/"); err != nil {
+		t.Fatalf("trimPathPrefix() failed: %v", err)
+	}
+	var paths []string
+	for path := range swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if want := []string{"/", "/api/v10/pets", "/health", "/pets"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths after trimming = %v, want %v", paths, want)
+	}
+
+	swagger = parseTestSpec(t, spec, false)
+	swagger.Paths["/pets"] = swagger.Paths["/health"]
+	if err := trimPathPrefix(swagger, "api/v1"); err == nil {
+		t.Error("trimPathPrefix() accepted a prefix that makes two paths collide")
+	}
+}
+
+func TestOperationsByPathPrefix(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "groups", "version": "1"},
+		"paths": {
+			"/v1/pets": {"get": {"responses": {"200": {"description": "ok"}}}, "post": {"responses": {"201": {"description": "ok"}}}},
+			"/v1/pets/{id}": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/v1/stores": {"get": {"responses": {"200": {"description": "ok"}}}},
+			"/health": {"get": {"responses": {"200": {"description": "ok"}}}}
+		}
+	}`, false)
+	summarize := func(groups map[string][]pathOperation) map[string][]string {
+		got := make(map[string][]string)
+		for prefix, ops := range groups {
+			for _, op := range ops {
+				got[prefix] = append(got[prefix], op.Method+" "+op.Path)
+			}
+		}
+		return got
+	}
+	groups, err := operationsByPathPrefix(swagger, 2)
+	if err != nil {
+		t.Fatalf("operationsByPathPrefix(2) failed: %v", err)
+	}
+	want := map[string][]string{
+		"/v1/pets":   {"GET /v1/pets", "POST /v1/pets", "GET /v1/pets/{id}"},
+		"/v1/stores": {"GET /v1/stores"},
+		"/health":    {"GET /health"},
+	}
+	if got := summarize(groups); !reflect.DeepEqual(got, want) {
+		t.Errorf("operationsByPathPrefix(2) = %v, want %v", got, want)
+	}
+	groups, err = operationsByPathPrefix(swagger)
+	if err != nil {
+		t.Fatalf("operationsByPathPrefix() failed: %v", err)
+	}
+	if got := summarize(groups); len(got["/v1"]) != 4 || len(got["/health"]) != 1 {
+		t.Errorf("operationsByPathPrefix() = %v, want /v1 and /health groups", got)
+	}
+	if _, err := operationsByPathPrefix(swagger, -1); err == nil {
+		t.Error("operationsByPathPrefix(-1) did not fail")
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"plugin"
+	tplText "text/template"
+)
+
+// loadPluginFuncs opens the Go plugin at fn and returns the template
+// functions provided by its exported Funcs function. html/template.FuncMap
+// is an alias of text/template.FuncMap, so either one may be returned.
+func loadPluginFuncs(fn string) (map[string]interface{}, error) {
+	p, err := plugin.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open plugin %s: %w", fn, err)
+	}
+	sym, err := p.Lookup("Funcs")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s does not export Funcs: %w", fn, err)
+	}
+	switch f := sym.(type) {
+	case func() tplText.FuncMap:
+		return f(), nil
+	case func() map[string]interface{}:
+		return f(), nil
+	}
+	return nil, fmt.Errorf("plugin %s: Funcs has type %T, expected func() template.FuncMap", fn, sym)
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPluginFuncs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"plugin/main.go": `package main
+
+import "text/template"
+
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"shout": func(s string) string { return s + "!" },
+		"camel": func(s string) string { return "plugin" },
+	}
+}
+`,
+		"spec.json":         petstoreSpec,
+		"tpl/title.txt.tpl": `{{ shout .Info.Title }} {{ camel "pet_store" }}`,
+	})
+	so := filepath.Join(dir, "funcs.so")
+	args := []string{"build", "-buildmode=plugin", "-o", so}
+	if raceEnabled {
+		args = append(args, "-race")
+	}
+	build := exec.Command("go", append(args, "main.go")...)
+	build.Dir = filepath.Join(dir, "plugin")
+	build.Env = append(os.Environ(), "GO111MODULE=off")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Skipf("cannot build plugin: %v: %s", err, out)
+	}
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out", "-plugin", so); code != 0 {
+		// Plugins only load into binaries built with the same toolchain and
+		// flags, which instrumented test binaries, e.g. -cover, may not match.
+		if strings.Contains(out, "plugin was built with a different version of package") {
+			t.Skipf("plugin does not match the test binary: %s", out)
+		}
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	if got, want := readTestFile(t, filepath.Join(dir, "out", "title.txt")), "Pet Store! PetStore"; got != want {
+		t.Errorf("title.txt = %q, want %q: plugin functions must not override built-ins", got, want)
+	}
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-plugin", filepath.Join(dir, "missing.so")); code == 0 {
+		t.Errorf("a missing plugin should fail: %s", out)
+	}
+}
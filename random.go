@@ -0,0 +1,62 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"reflect"
+)
+
+// templateRand returns the pseudo-random source of a template. It derives
+// from both the run seed and the template path, so that adding or removing
+// templates does not change the values drawn by the others.
+func templateRand(seed int64, path string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(path))
+	return rand.New(rand.NewSource(seed ^ int64(h.Sum64())))
+}
+
+const randAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randFuncs builds the random data helpers backed by r.
+func randFuncs(r *rand.Rand) map[string]interface{} {
+	return map[string]interface{}{
+		"randInt": func(min, max int) (int, error) {
+			if max < min {
+				return 0, fmt.Errorf("randInt: max %d is less than min %d", max, min)
+			}
+			return min + r.Intn(max-min+1), nil
+		},
+		"randString": func(n int) string {
+			b := make([]byte, n)
+			for i := range b {
+				b[i] = randAlphabet[r.Intn(len(randAlphabet))]
+			}
+			return string(b)
+		},
+		"randFrom": func(list interface{}) (interface{}, error) {
+			v := reflect.ValueOf(list)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return nil, fmt.Errorf("randFrom: cannot pick from %T", list)
+			}
+			if v.Len() == 0 {
+				return nil, nil
+			}
+			return v.Index(r.Intn(v.Len())).Interface(), nil
+		},
+	}
+}
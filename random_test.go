@@ -0,0 +1,44 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeedIsReproducible(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":        petstoreSpec,
+		"tpl/rand.txt.tpl": "{{ randInt 0 1000000 }} {{ randString 16 }} {{ randFrom allSchemaNames }}",
+	})
+	render := func(output, seed string) string {
+		t.Helper()
+		if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", output, "-seed", seed); code != 0 {
+			t.Fatalf("openapigen failed: %s", out)
+		}
+		return readTestFile(t, filepath.Join(dir, output, "rand.txt"))
+	}
+	first, second := render("first", "42"), render("second", "42")
+	if first != second {
+		t.Errorf("same seed rendered %q and %q", first, second)
+	}
+	if other := render("other", "43"); other == first {
+		t.Errorf("seeds 42 and 43 both rendered %q", first)
+	}
+}
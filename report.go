@@ -0,0 +1,53 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// runReport summarizes a run for -report.
+type runReport struct {
+	TemplatesRendered int   `json:"templatesRendered"`
+	FilesWritten      int   `json:"filesWritten"`
+	FilesUnchanged    int   `json:"filesUnchanged"`
+	FilesSkipped      int   `json:"filesSkipped"`
+	Warnings          int   `json:"warnings"`
+	DurationMillis    int64 `json:"durationMillis"`
+}
+
+// sameContent reports whether the file fn exists and its SHA-256 is sum.
+func sameContent(fn, sum string) bool {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]) == sum
+}
+
+func writeReport(fn string, r *runReport, start time.Time) error {
+	r.DurationMillis = time.Since(start).Nanoseconds() / int64(time.Millisecond)
+	b, err := json.MarshalIndent(r, "", "	")
+	if err != nil {
+		return fmt.Errorf("cannot encode report: %w", err)
+	}
+	return ioutil.WriteFile(fn, append(b, '\n'), 0644)
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         strings.Replace(petstoreSpec, `"operationId": "listPets", `, "", 1),
+		"tpl/pets.txt.tpl":  "pets",
+		"tpl/blank.txt.tpl": "  \n",
+	})
+	readReport := func(fn string) runReport {
+		t.Helper()
+		var r runReport
+		if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, fn))), &r); err != nil {
+			t.Fatalf("cannot decode %s: %v", fn, err)
+		}
+		return r
+	}
+	for i, fn := range []string{"first.json", "second.json"} {
+		if out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out", "-skip-empty", "-report", fn); code != 0 {
+			t.Fatalf("run %d failed: %s", i+1, out)
+		}
+	}
+	tests := []struct {
+		fn   string
+		want runReport
+	}{
+		{"first.json", runReport{TemplatesRendered: 2, FilesWritten: 1, FilesSkipped: 1, Warnings: 1}},
+		{"second.json", runReport{TemplatesRendered: 2, FilesUnchanged: 1, FilesSkipped: 1, Warnings: 1}},
+	}
+	for _, tt := range tests {
+		got := readReport(tt.fn)
+		if got.DurationMillis < 0 {
+			t.Errorf("%s: durationMillis = %d, want a non-negative duration", tt.fn, got.DurationMillis)
+		}
+		got.DurationMillis = 0
+		if got != tt.want {
+			t.Errorf("%s = %+v, want %+v", tt.fn, got, tt.want)
+		}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, "first.json"))), &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"templatesRendered", "filesWritten", "filesUnchanged", "filesSkipped", "warnings", "durationMillis"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("report lacks the %s field", name)
+		}
+	}
+}
@@ -0,0 +1,785 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaRefOf normalizes the values templates usually hold when walking a
+// spec (*openapi3.SchemaRef or *openapi3.Schema) into a *openapi3.SchemaRef.
+// It returns nil for anything else.
+func schemaRefOf(v interface{}) *openapi3.SchemaRef {
+	switch s := v.(type) {
+	case *openapi3.SchemaRef:
+		return s
+	case *openapi3.Schema:
+		if s == nil {
+			return nil
+		}
+		return &openapi3.SchemaRef{Value: s}
+	}
+	return nil
+}
+
+// schemaOf returns the schema value behind v, or nil when there is none.
+func schemaOf(v interface{}) *openapi3.Schema {
+	ref := schemaRefOf(v)
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+// refName returns the bare component name of a $ref, regardless of whether
+// it points to v2 definitions or v3 components, or into another document.
+// JSON pointer escapes (~0 and ~1) are decoded. The name is the one of the
+// spec, untouched by -schema-name-map: pass it to goName for the Go type name.
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// schemaRef builds the $ref pointing to the named schema. It is the inverse
+// of refName.
+func schemaRef(name string, v2 bool) string {
+	if v2 {
+		return "#/definitions/" + name
+	}
+	return "#/components/schemas/" + name
+}
+
+const (
+	// goTypeExtension names the Go type used for a schema instead of the
+	// derived one, e.g. uuid.UUID.
+	goTypeExtension = "x-go-type"
+	// goImportExtension names the package to import for goTypeExtension,
+	// e.g. github.com/google/uuid.
+	goImportExtension = "x-go-import"
+)
+
+// goImports returns, sorted and deduplicated, the x-go-import packages of
+// the given schemas and the schemas nested in them. Without arguments, every
+// component schema is considered.
+func goImports(swagger *openapi3.T, args ...interface{}) []string {
+	imports := []string{}
+	if len(args) == 0 {
+		for _, name := range allSchemaNames(swagger) {
+			args = append(args, swagger.Components.Schemas[name])
+		}
+	}
+	visited := make(map[*openapi3.Schema]bool)
+	for _, arg := range args {
+		walkSchemaRef(schemaRefOf(arg), visited, func(ref *openapi3.SchemaRef) {
+			if ref.Value == nil {
+				return
+			}
+			if imp := extensionString(ref.Value.Extensions, goImportExtension); imp != "" && !containsString(imports, imp) {
+				imports = append(imports, imp)
+			}
+		})
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// schemaNameOverrides maps schema names to the Go type names set with
+// -schema-name-map.
+var schemaNameOverrides map[string]string
+
+// parseSchemaNameMap parses the oldName=NewName pairs given to
+// -schema-name-map.
+func parseSchemaNameMap(pairs []string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid schema name mapping %q: expected oldName=NewName", pair)
+		}
+		overrides[parts[0]] = parts[1]
+	}
+	return overrides, nil
+}
+
+// goName returns the Go type name of the named schema: its -schema-name-map
+// override, or else its camel-cased name. It is the only name the overrides
+// apply to; refName, referencedSchemas and the other helpers returning
+// schema names keep the names of the spec.
+func goName(name string) string {
+	if override, ok := schemaNameOverrides[name]; ok {
+		return override
+	}
+	return toCamel(name)
+}
+
+// goType renders the Go type that best represents the given schema. The
+// x-go-type extension overrides it entirely. References are otherwise
+// rendered by their Go type name, see goName.
+func goType(v interface{}) string {
+	ref := schemaRefOf(v)
+	if ref == nil {
+		return "interface{}"
+	}
+	if ref.Value != nil {
+		if override := extensionString(ref.Value.Extensions, goTypeExtension); override != "" {
+			return override
+		}
+	}
+	if ref.Ref != "" {
+		return goName(refName(ref.Ref))
+	}
+	schema := ref.Value
+	if schema == nil {
+		return "interface{}"
+	}
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer", "number":
+		return numericType(schema)
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + goType(schema.Items)
+	case "object", "":
+		if isOpenMap(ref) {
+			return "map[string]" + mapValueType(ref)
+		}
+		if schema.Type == "object" {
+			return "map[string]interface{}"
+		}
+	}
+	return "interface{}"
+}
+
+// schemaType returns the type of the schema as a single string. OpenAPI 3.1
+// type arrays are reduced to their first non-null type when the spec is
+// loaded, see normalizeTypeArrays. Untyped schemas are typed after their
+// structure: object when they declare properties, array when they declare
+// items; otherwise the result is empty.
+func schemaType(v interface{}) string {
+	schema := schemaOf(v)
+	switch {
+	case schema == nil:
+		return ""
+	case schema.Type != "":
+		return schema.Type
+	case len(schema.Properties) > 0 || schema.AdditionalProperties.Schema != nil:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// numericType renders the Go type of an integer or number schema according
+// to its format: int32 and int64 integers, float and double numbers. Integers
+// without a known format are int, numbers float64. Other schemas yield an
+// empty string.
+func numericType(v interface{}) string {
+	schema := schemaOf(v)
+	if schema == nil {
+		return ""
+	}
+	switch schema.Type {
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	}
+	return ""
+}
+
+// isOpenMap reports whether the schema is a free-form dictionary, that is, an
+// object whose additionalProperties is either a schema or true.
+func isOpenMap(v interface{}) bool {
+	schema := schemaOf(v)
+	if schema == nil || (schema.Type != "object" && schema.Type != "") {
+		return false
+	}
+	addProps := schema.AdditionalProperties
+	return addProps.Schema != nil || (addProps.Has != nil && *addProps.Has)
+}
+
+// disallowsAdditional reports whether the schema explicitly sets
+// additionalProperties to false. An unset additionalProperties allows
+// additional properties.
+func disallowsAdditional(v interface{}) bool {
+	schema := schemaOf(v)
+	if schema == nil {
+		return false
+	}
+	addProps := schema.AdditionalProperties
+	return addProps.Schema == nil && addProps.Has != nil && !*addProps.Has
+}
+
+// anyOfMembers returns the member schemas of the anyOf union. The references
+// are returned, rather than their values, so goType keeps rendering named
+// types by name.
+func anyOfMembers(v interface{}) []*openapi3.SchemaRef {
+	schema := schemaOf(v)
+	if schema == nil {
+		return nil
+	}
+	var members []*openapi3.SchemaRef
+	for _, member := range schema.AnyOf {
+		if member != nil && member.Value != nil {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// isFreeForm reports whether the schema accepts any value: the empty schema
+// {}, or an object without properties whose additionalProperties is unset or
+// true.
+func isFreeForm(v interface{}) bool {
+	schema := schemaOf(v)
+	if schema == nil || (schema.Type != "" && schema.Type != "object") {
+		return false
+	}
+	if len(schema.Properties) > 0 || len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 ||
+		len(schema.OneOf) > 0 || schema.Not != nil || len(schema.Enum) > 0 || schema.Items != nil {
+		return false
+	}
+	addProps := schema.AdditionalProperties
+	return addProps.Schema == nil && (addProps.Has == nil || *addProps.Has)
+}
+
+// isEmptyObject reports whether the schema is an object declaring neither
+// properties, composition nor an additionalProperties schema or true.
+func isEmptyObject(v interface{}) bool {
+	schema := schemaOf(v)
+	if schema == nil || schema.Type != "object" {
+		return false
+	}
+	if len(schema.Properties) > 0 || len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 {
+		return false
+	}
+	addProps := schema.AdditionalProperties
+	return addProps.Schema == nil && (addProps.Has == nil || !*addProps.Has)
+}
+
+// mapValueType renders the Go type of the values of a free-form dictionary.
+// When additionalProperties is true, any value is accepted.
+func mapValueType(v interface{}) string {
+	schema := schemaOf(v)
+	if schema == nil || schema.AdditionalProperties.Schema == nil {
+		return "interface{}"
+	}
+	return goType(schema.AdditionalProperties.Schema)
+}
+
+// resolveSchema returns a deep copy of the given schema with every $ref
+// dereferenced and every allOf merged into its parent, so templates can walk
+// the result without following references. Cyclic references are cut at the
+// point the cycle closes, leaving a copy of that schema without its children.
+// Nesting that reaches maxDepth, the given schema being at depth 0, is
+// reported as an error, as with depthGuard.
+func resolveSchema(v interface{}, maxDepth int) (*openapi3.Schema, error) {
+	r := &schemaResolver{
+		visiting: make(map[*openapi3.Schema]bool),
+		maxDepth: maxDepth,
+	}
+	return r.resolve(schemaOf(v), 0)
+}
+
+type schemaResolver struct {
+	visiting map[*openapi3.Schema]bool
+	maxDepth int
+}
+
+func (r *schemaResolver) resolve(schema *openapi3.Schema, depth int) (*openapi3.Schema, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if depth >= r.maxDepth {
+		return nil, errMaxDepth(r.maxDepth)
+	}
+	resolved := *schema
+	if r.visiting[schema] {
+		resolved.OneOf, resolved.AnyOf, resolved.AllOf = nil, nil, nil
+		resolved.Not, resolved.Items = nil, nil
+		resolved.Properties = nil
+		resolved.AdditionalProperties.Schema = nil
+		return &resolved, nil
+	}
+	r.visiting[schema] = true
+	defer delete(r.visiting, schema)
+	var err error
+	resolveRef := func(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+		if ref == nil || err != nil {
+			return nil
+		}
+		var value *openapi3.Schema
+		value, err = r.resolve(ref.Value, depth+1)
+		return &openapi3.SchemaRef{Value: value}
+	}
+	resolveRefs := func(refs openapi3.SchemaRefs) openapi3.SchemaRefs {
+		if refs == nil {
+			return nil
+		}
+		out := make(openapi3.SchemaRefs, 0, len(refs))
+		for _, ref := range refs {
+			out = append(out, resolveRef(ref))
+		}
+		return out
+	}
+	resolved.Enum = append([]interface{}(nil), schema.Enum...)
+	resolved.Required = append([]string(nil), schema.Required...)
+	resolved.OneOf = resolveRefs(schema.OneOf)
+	resolved.AnyOf = resolveRefs(schema.AnyOf)
+	resolved.AllOf = nil
+	resolved.Not = resolveRef(schema.Not)
+	resolved.Items = resolveRef(schema.Items)
+	resolved.AdditionalProperties.Schema = resolveRef(schema.AdditionalProperties.Schema)
+	if schema.Properties != nil {
+		resolved.Properties = make(openapi3.Schemas, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			resolved.Properties[name] = resolveRef(prop)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range schema.AllOf {
+		if member == nil {
+			continue
+		}
+		part, err := r.resolve(member.Value, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if part == nil {
+			continue
+		}
+		if resolved.Type == "" {
+			resolved.Type = part.Type
+		}
+		if resolved.Description == "" {
+			resolved.Description = part.Description
+		}
+		if len(part.Properties) > 0 && resolved.Properties == nil {
+			resolved.Properties = make(openapi3.Schemas, len(part.Properties))
+		}
+		for name, prop := range part.Properties {
+			if _, ok := resolved.Properties[name]; !ok {
+				resolved.Properties[name] = prop
+			}
+		}
+		for _, name := range part.Required {
+			if !containsString(resolved.Required, name) {
+				resolved.Required = append(resolved.Required, name)
+			}
+		}
+	}
+	return &resolved, nil
+}
+
+func errMaxDepth(maxDepth int) error {
+	return fmt.Errorf("schema nesting exceeds the maximum depth of %d (see -max-depth)", maxDepth)
+}
+
+// depthGuard lets recursive templates bound their own recursion: it returns
+// depth+1, or an error once depth reaches maxDepth.
+func depthGuard(depth, maxDepth int) (int, error) {
+	if depth >= maxDepth {
+		return 0, errMaxDepth(maxDepth)
+	}
+	return depth + 1, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaConstraints holds the validation facets of a schema. Unset facets
+// are nil so templates can tell them apart from zero values.
+type schemaConstraints struct {
+	MinLength        *uint64
+	MaxLength        *uint64
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+	MultipleOf       *float64
+	Pattern          *string
+	MinItems         *uint64
+	MaxItems         *uint64
+}
+
+// constraints returns the validation facets declared by the schema.
+func constraints(v interface{}) schemaConstraints {
+	var c schemaConstraints
+	schema := schemaOf(v)
+	if schema == nil {
+		return c
+	}
+	if schema.MinLength > 0 {
+		minLength := schema.MinLength
+		c.MinLength = &minLength
+	}
+	if schema.MinItems > 0 {
+		minItems := schema.MinItems
+		c.MinItems = &minItems
+	}
+	if schema.Pattern != "" {
+		pattern := schema.Pattern
+		c.Pattern = &pattern
+	}
+	c.MaxLength = schema.MaxLength
+	c.Minimum = schema.Min
+	c.Maximum = schema.Max
+	c.ExclusiveMinimum = schema.ExclusiveMin
+	c.ExclusiveMaximum = schema.ExclusiveMax
+	c.MultipleOf = schema.MultipleOf
+	c.MaxItems = schema.MaxItems
+	return c
+}
+
+// extensionString returns the value of a vendor extension as a string.
+func extensionString(extensions map[string]interface{}, name string) string {
+	switch v := extensions[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.RawMessage:
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return s
+		}
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// componentSchemas returns the schemas declared in the spec components.
+func componentSchemas(swagger *openapi3.T) openapi3.Schemas {
+	if swagger == nil || swagger.Components == nil {
+		return nil
+	}
+	return swagger.Components.Schemas
+}
+
+// walkSchemaRef calls fn for ref and every schema reference nested in it.
+// Schemas already in visited are not descended into again, which cuts
+// cycles.
+func walkSchemaRef(ref *openapi3.SchemaRef, visited map[*openapi3.Schema]bool, fn func(*openapi3.SchemaRef)) {
+	if ref == nil {
+		return
+	}
+	fn(ref)
+	schema := ref.Value
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+	for _, prop := range schema.Properties {
+		walkSchemaRef(prop, visited, fn)
+	}
+	walkSchemaRef(schema.Items, visited, fn)
+	walkSchemaRef(schema.Not, visited, fn)
+	walkSchemaRef(schema.AdditionalProperties.Schema, visited, fn)
+	for _, list := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, member := range list {
+			walkSchemaRef(member, visited, fn)
+		}
+	}
+}
+
+// referencedSchemas returns, sorted, the names of the schemas reachable from
+// the operations of the spec, following references transitively.
+func referencedSchemas(swagger *openapi3.T) []string {
+	names := []string{}
+	if swagger == nil {
+		return names
+	}
+	seen := make(map[string]bool)
+	visited := make(map[*openapi3.Schema]bool)
+	walk := func(ref *openapi3.SchemaRef) {
+		walkSchemaRef(ref, visited, func(ref *openapi3.SchemaRef) {
+			if ref.Ref == "" || !(strings.Contains(ref.Ref, "/schemas/") || strings.Contains(ref.Ref, "/definitions/")) {
+				return
+			}
+			if name := refName(ref.Ref); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		})
+	}
+	walkContent := func(content openapi3.Content) {
+		for _, mt := range content {
+			if mt != nil {
+				walk(mt.Schema)
+			}
+		}
+	}
+	for _, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, op := range pathItem.Operations() {
+			for _, param := range operationParameters(swagger, op) {
+				if p := paramOf(param); p != nil {
+					walk(p.Schema)
+					walkContent(p.Content)
+				}
+			}
+			if body := requestBody(op); body != nil {
+				walkContent(body.Content)
+			}
+			for _, resp := range op.Responses {
+				if resp == nil || resp.Value == nil {
+					continue
+				}
+				walkContent(resp.Value.Content)
+				for _, header := range resp.Value.Headers {
+					if header != nil && header.Value != nil {
+						walk(header.Value.Schema)
+						walkContent(header.Value.Content)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemasByExtension groups the component schema names, sorted, by the value
+// of the given vendor extension. Schemas without the extension are grouped
+// under the empty string.
+func schemasByExtension(swagger *openapi3.T, extension string) map[string][]string {
+	groups := make(map[string][]string)
+	for name, ref := range componentSchemas(swagger) {
+		var value string
+		if ref != nil && ref.Value != nil {
+			value = extensionString(ref.Value.Extensions, extension)
+		}
+		groups[value] = append(groups[value], name)
+	}
+	for _, names := range groups {
+		sort.Strings(names)
+	}
+	return groups
+}
+
+// derefSchema returns the schema behind v, looking unresolved $refs up in
+// the spec components.
+func derefSchema(swagger *openapi3.T, v interface{}) *openapi3.Schema {
+	ref := schemaRefOf(v)
+	if ref == nil {
+		return nil
+	}
+	if ref.Value == nil && ref.Ref != "" {
+		if target := componentSchemas(swagger)[refName(ref.Ref)]; target != nil {
+			return target.Value
+		}
+	}
+	return ref.Value
+}
+
+// schemaTitle returns the title of the schema, following $refs.
+func schemaTitle(swagger *openapi3.T, v interface{}) string {
+	if schema := derefSchema(swagger, v); schema != nil {
+		return schema.Title
+	}
+	return ""
+}
+
+// schemaDescription returns the description of the schema, following $refs.
+func schemaDescription(swagger *openapi3.T, v interface{}) string {
+	if schema := derefSchema(swagger, v); schema != nil {
+		return schema.Description
+	}
+	return ""
+}
+
+// isReadOnly reports whether the schema is marked readOnly.
+func isReadOnly(v interface{}) bool {
+	schema := schemaOf(v)
+	return schema != nil && schema.ReadOnly
+}
+
+// isWriteOnly reports whether the schema is marked writeOnly.
+func isWriteOnly(v interface{}) bool {
+	schema := schemaOf(v)
+	return schema != nil && schema.WriteOnly
+}
+
+// arrayItems returns the schema of the elements of the array schema, with
+// references followed, or nil for anything but an array.
+func arrayItems(v interface{}) *openapi3.Schema {
+	schema := schemaOf(v)
+	if schema == nil || schema.Type != "array" || schema.Items == nil {
+		return nil
+	}
+	return schema.Items.Value
+}
+
+// uniqueItems reports whether the array schema requires unique items.
+func uniqueItems(v interface{}) bool {
+	schema := schemaOf(v)
+	return schema != nil && schema.UniqueItems
+}
+
+// itemsEnum returns the allowed values of the items of the array schema, or
+// nil when the items are not an enum.
+func itemsEnum(v interface{}) []interface{} {
+	schema := schemaOf(v)
+	if schema == nil || schema.Items == nil || schema.Items.Value == nil {
+		return nil
+	}
+	return schema.Items.Value.Enum
+}
+
+// filterProperties returns the properties of the schema for which keep
+// returns true.
+func filterProperties(v interface{}, keep func(*openapi3.SchemaRef) bool) openapi3.Schemas {
+	props := make(openapi3.Schemas)
+	schema := schemaOf(v)
+	if schema == nil {
+		return props
+	}
+	for name, prop := range schema.Properties {
+		if keep(prop) {
+			props[name] = prop
+		}
+	}
+	return props
+}
+
+// requestProperties returns the properties of the schema that may be sent in
+// a request, that is, those not marked readOnly.
+func requestProperties(v interface{}) openapi3.Schemas {
+	return filterProperties(v, func(prop *openapi3.SchemaRef) bool { return !isReadOnly(prop) })
+}
+
+// responseProperties returns the properties of the schema that may be
+// received in a response, that is, those not marked writeOnly.
+func responseProperties(v interface{}) openapi3.Schemas {
+	return filterProperties(v, func(prop *openapi3.SchemaRef) bool { return !isWriteOnly(prop) })
+}
+
+// hasDefault reports whether the schema declares a default value.
+func hasDefault(v interface{}) bool {
+	schema := schemaOf(v)
+	return schema != nil && schema.Default != nil
+}
+
+// defaultValue returns the default value declared by the schema, as decoded
+// from the spec.
+func defaultValue(v interface{}) interface{} {
+	schema := schemaOf(v)
+	if schema == nil {
+		return nil
+	}
+	return schema.Default
+}
+
+// exampleFor produces a representative value for the schema: its example,
+// else its first enum value, else a value derived from its type and format.
+// Objects are filled property by property and arrays hold a single element.
+func exampleFor(v interface{}, maxDepth int) (interface{}, error) {
+	schema, err := resolveSchema(v, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	return exampleForResolved(schema), nil
+}
+
+func exampleForResolved(schema *openapi3.Schema) interface{} {
+	if schema == nil {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	for _, alternatives := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf} {
+		if len(alternatives) > 0 && alternatives[0] != nil {
+			return exampleForResolved(alternatives[0].Value)
+		}
+	}
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "1970-01-01T00:00:00Z"
+		case "date":
+			return "1970-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{exampleForResolved(schema.Items.Value)}
+	}
+	if schema.Type == "object" || len(schema.Properties) > 0 {
+		obj := make(map[string]interface{}, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if prop != nil {
+				obj[name] = exampleForResolved(prop.Value)
+			}
+		}
+		if addProps := schema.AdditionalProperties.Schema; len(obj) == 0 && addProps != nil {
+			obj["key"] = exampleForResolved(addProps.Value)
+		}
+		return obj
+	}
+	return nil
+}
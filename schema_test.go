@@ -0,0 +1,738 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGoTypeAdditionalProperties(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "maps", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"ByRef": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/Pet"}},
+			"Inline": {"type": "object", "additionalProperties": {"type": "integer", "format": "int64"}},
+			"Any": {"type": "object", "additionalProperties": true},
+			"Closed": {"type": "object", "additionalProperties": false}
+		}}
+	}`, false)
+	tests := []struct {
+		name      string
+		isOpenMap bool
+		valueType string
+		goType    string
+	}{
+		{"ByRef", true, "Pet", "map[string]Pet"},
+		{"Inline", true, "int64", "map[string]int64"},
+		{"Any", true, "interface{}", "map[string]interface{}"},
+		{"Closed", false, "interface{}", "map[string]interface{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema(t, swagger, tt.name).Value
+			if got := isOpenMap(schema); got != tt.isOpenMap {
+				t.Errorf("isOpenMap() = %v, want %v", got, tt.isOpenMap)
+			}
+			if got := mapValueType(schema); got != tt.valueType {
+				t.Errorf("mapValueType() = %q, want %q", got, tt.valueType)
+			}
+			if got := goType(schema); got != tt.goType {
+				t.Errorf("goType() = %q, want %q", got, tt.goType)
+			}
+		})
+	}
+}
+
+func TestResolveSchema(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "refs", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Name": {"type": "string", "description": "a name"},
+			"Base": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}},
+			"Tag": {"type": "object", "properties": {"label": {"$ref": "#/components/schemas/Name"}}},
+			"Pet": {
+				"allOf": [{"$ref": "#/components/schemas/Base"}],
+				"type": "object",
+				"properties": {
+					"name": {"$ref": "#/components/schemas/Name"},
+					"tags": {"type": "array", "items": {"$ref": "#/components/schemas/Tag"}}
+				}
+			}
+		}}
+	}`, false)
+	resolved, err := resolveSchema(testSchema(t, swagger, "Pet"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved.AllOf) != 0 {
+		t.Errorf("allOf was not merged: %v", resolved.AllOf)
+	}
+	if !containsString(resolved.Required, "id") {
+		t.Errorf("required = %v, want it to include id from Base", resolved.Required)
+	}
+	for _, name := range []string{"id", "name", "tags"} {
+		if resolved.Properties[name] == nil {
+			t.Errorf("property %s missing", name)
+		}
+	}
+	if got := resolved.Properties["name"].Value.Description; got != "a name" {
+		t.Errorf("name description = %q, want the one of Name", got)
+	}
+	label := resolved.Properties["tags"].Value.Items.Value.Properties["label"]
+	if label == nil || label.Value == nil || label.Value.Type != "string" {
+		t.Fatalf("tags[].label did not resolve to a string: %+v", label)
+	}
+	var checkNoRefs func(path string, ref *openapi3.SchemaRef)
+	checkNoRefs = func(path string, ref *openapi3.SchemaRef) {
+		if ref == nil {
+			return
+		}
+		if ref.Ref != "" {
+			t.Errorf("%s still refers to %s", path, ref.Ref)
+		}
+		if ref.Value == nil {
+			return
+		}
+		for name, prop := range ref.Value.Properties {
+			checkNoRefs(path+"."+name, prop)
+		}
+		checkNoRefs(path+"[]", ref.Value.Items)
+	}
+	checkNoRefs("Pet", &openapi3.SchemaRef{Value: resolved})
+	if original := testSchema(t, swagger, "Pet").Value; len(original.AllOf) != 1 {
+		t.Errorf("resolveSchema modified the original schema")
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "constraints", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Code": {"type": "string", "pattern": "^[A-Z]{3}$", "maxLength": 3},
+			"Free": {"type": "string"}
+		}}
+	}`, false)
+	c := constraints(testSchema(t, swagger, "Code"))
+	if c.Pattern == nil || *c.Pattern != "^[A-Z]{3}$" {
+		t.Errorf("Pattern = %v, want ^[A-Z]{3}$", c.Pattern)
+	}
+	if c.MaxLength == nil || *c.MaxLength != 3 {
+		t.Errorf("MaxLength = %v, want 3", c.MaxLength)
+	}
+	if c.MinLength != nil || c.Minimum != nil || c.Maximum != nil {
+		t.Errorf("unset facets should be nil: %+v", c)
+	}
+	free := constraints(testSchema(t, swagger, "Free"))
+	if free.Pattern != nil || free.MaxLength != nil {
+		t.Errorf("constraints(Free) = %+v, want no facets", free)
+	}
+}
+
+func TestSchemasByExtension(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "modules", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "x-module": "pets"},
+			"Toy": {"type": "object", "x-module": "pets"},
+			"Order": {"type": "object", "x-module": "store"},
+			"Error": {"type": "object"}
+		}}
+	}`, false)
+	got := schemasByExtension(swagger, "x-module")
+	want := map[string][]string{
+		"pets":  {"Pet", "Toy"},
+		"store": {"Order"},
+		"":      {"Error"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schemasByExtension(x-module) = %v, want %v", got, want)
+	}
+}
+
+func TestSchemaRef(t *testing.T) {
+	tests := []struct {
+		v2   bool
+		want string
+	}{
+		{true, "#/definitions/Pet"},
+		{false, "#/components/schemas/Pet"},
+	}
+	for _, tt := range tests {
+		got := schemaRef("Pet", tt.v2)
+		if got != tt.want {
+			t.Errorf("schemaRef(Pet, v2=%v) = %q, want %q", tt.v2, got, tt.want)
+		}
+		if name := refName(got); name != "Pet" {
+			t.Errorf("refName(%q) = %q, want Pet", got, name)
+		}
+	}
+}
+
+func TestSchemaTitleAndDescription(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "docs", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "title": "A pet", "description": "Pets live in the store."},
+			"Owner": {"type": "object", "properties": {
+				"pet": {"$ref": "#/components/schemas/Pet"},
+				"nick": {"type": "string", "title": "Nickname", "description": "What friends call them."}
+			}}
+		}}
+	}`, false)
+	props := testSchema(t, swagger, "Owner").Value.Properties
+	tests := []struct {
+		name      string
+		v         interface{}
+		wantTitle string
+		wantDesc  string
+	}{
+		{"inline", props["nick"], "Nickname", "What friends call them."},
+		{"ref", props["pet"], "A pet", "Pets live in the store."},
+		{"unresolved ref", &openapi3.SchemaRef{Ref: "#/components/schemas/Pet"}, "A pet", "Pets live in the store."},
+		{"nil", nil, "", ""},
+	}
+	for _, tt := range tests {
+		if got := schemaTitle(swagger, tt.v); got != tt.wantTitle {
+			t.Errorf("%s: schemaTitle() = %q, want %q", tt.name, got, tt.wantTitle)
+		}
+		if got := schemaDescription(swagger, tt.v); got != tt.wantDesc {
+			t.Errorf("%s: schemaDescription() = %q, want %q", tt.name, got, tt.wantDesc)
+		}
+	}
+}
+
+func TestRequestAndResponseProperties(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "access", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {"User": {"type": "object", "properties": {
+			"id": {"type": "string", "readOnly": true},
+			"password": {"type": "string", "writeOnly": true},
+			"name": {"type": "string"}
+		}}}}
+	}`, false)
+	user := testSchema(t, swagger, "User")
+	tests := []struct {
+		name  string
+		props openapi3.Schemas
+		want  []string
+	}{
+		{"requestProperties", requestProperties(user), []string{"name", "password"}},
+		{"responseProperties", responseProperties(user), []string{"id", "name"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for name := range tt.props {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSchemaMaxDepth(t *testing.T) {
+	var schema *openapi3.Schema
+	for i := 0; i < 10; i++ {
+		schema = &openapi3.Schema{Type: "array", Items: &openapi3.SchemaRef{Value: orString(schema)}}
+	}
+	if _, err := resolveSchema(schema, 16); err != nil {
+		t.Errorf("resolveSchema within the limit failed: %v", err)
+	}
+	if _, err := resolveSchema(schema, 5); err == nil {
+		t.Error("resolveSchema beyond the limit should fail")
+	}
+	if _, err := exampleFor(schema, 5); err == nil {
+		t.Error("exampleFor beyond the limit should fail")
+	}
+	// The schema nests 11 levels, at depths 0 to 10: a limit of 10 is
+	// reached, as depthGuard would be after 10 calls.
+	if _, err := resolveSchema(schema, 11); err != nil {
+		t.Errorf("resolveSchema just below the limit failed: %v", err)
+	}
+	if _, err := resolveSchema(schema, 10); err == nil {
+		t.Error("resolveSchema at exactly the limit should fail")
+	}
+	if _, err := exampleFor(schema, 10); err == nil {
+		t.Error("exampleFor at exactly the limit should fail")
+	}
+	depth := 0
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		depth, err = depthGuard(depth, 3)
+	}
+	if err != nil || depth != 3 {
+		t.Errorf("depthGuard up to the limit = %d, %v; want 3, nil", depth, err)
+	}
+	if _, err := depthGuard(depth, 3); err == nil {
+		t.Error("depthGuard at the limit should fail")
+	}
+}
+
+// orString returns schema, or a string schema when it is nil.
+func orString(schema *openapi3.Schema) *openapi3.Schema {
+	if schema == nil {
+		return openapi3.NewStringSchema()
+	}
+	return schema
+}
+
+func TestDefaultValue(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "defaults", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Status": {"type": "string", "default": "active"},
+			"Limit": {"type": "integer", "default": 20},
+			"Name": {"type": "string"}
+		}}
+	}`, false)
+	tests := []struct {
+		name       string
+		hasDefault bool
+		want       interface{}
+	}{
+		{"Status", true, "active"},
+		{"Limit", true, float64(20)},
+		{"Name", false, nil},
+	}
+	for _, tt := range tests {
+		schema := testSchema(t, swagger, tt.name)
+		if got := hasDefault(schema); got != tt.hasDefault {
+			t.Errorf("hasDefault(%s) = %v, want %v", tt.name, got, tt.hasDefault)
+		}
+		if got := defaultValue(schema); got != tt.want {
+			t.Errorf("defaultValue(%s) = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExampleForNestedObject(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "examples", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Owner": {"type": "object", "properties": {
+				"email": {"type": "string", "format": "email"},
+				"age": {"type": "integer"}
+			}},
+			"Pet": {"type": "object", "properties": {
+				"name": {"type": "string", "example": "Rex"},
+				"kind": {"type": "string", "enum": ["dog", "cat"]},
+				"owner": {"$ref": "#/components/schemas/Owner"},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			}}
+		}}
+	}`, false)
+	got, err := exampleFor(testSchema(t, swagger, "Pet"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name": "Rex",
+		"kind": "dog",
+		"owner": map[string]interface{}{
+			"email": "user@example.com",
+			"age":   0,
+		},
+		"tags": []interface{}{"string"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("exampleFor(Pet) = %#v, want %#v", got, want)
+	}
+}
+
+func TestRefName(t *testing.T) {
+	tests := []struct {
+		ref, want string
+	}{
+		{"#/definitions/Pet", "Pet"},
+		{"#/components/schemas/Pet", "Pet"},
+		{"common.json#/components/schemas/Pet", "Pet"},
+		{"#/components/schemas/Pet~1Owner", "Pet/Owner"},
+		{"#/definitions/Tilde~0Name", "Tilde~Name"},
+	}
+	for _, tt := range tests {
+		if got := refName(tt.ref); got != tt.want {
+			t.Errorf("refName(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueItems(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "sets", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Tags": {"type": "array", "uniqueItems": true, "items": {"type": "string"}},
+			"Names": {"type": "array", "items": {"type": "string"}}
+		}}
+	}`, false)
+	if !uniqueItems(testSchema(t, swagger, "Tags")) {
+		t.Error("uniqueItems(Tags) = false, want true")
+	}
+	if uniqueItems(testSchema(t, swagger, "Names")) {
+		t.Error("uniqueItems(Names) = true, want false")
+	}
+	if uniqueItems(nil) {
+		t.Error("uniqueItems(nil) = true, want false")
+	}
+}
+
+func TestDisallowsAdditional(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "closed", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Unset": {"type": "object"},
+			"Open": {"type": "object", "additionalProperties": true},
+			"Closed": {"type": "object", "additionalProperties": false},
+			"Typed": {"type": "object", "additionalProperties": {"type": "string"}}
+		}}
+	}`, false)
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Unset", false},
+		{"Open", false},
+		{"Closed", true},
+		{"Typed", false},
+	}
+	for _, tt := range tests {
+		if got := disallowsAdditional(testSchema(t, swagger, tt.name)); got != tt.want {
+			t.Errorf("disallowsAdditional(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnyOfMembers(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "unions", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"ID": {"anyOf": [{"type": "string"}, {"type": "integer", "format": "int64"}]},
+			"Plain": {"type": "string"}
+		}}
+	}`, false)
+	members := anyOfMembers(testSchema(t, swagger, "ID"))
+	var types []string
+	for _, member := range members {
+		types = append(types, goType(member))
+	}
+	if want := []string{"string", "int64"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("anyOfMembers(ID) types = %v, want %v", types, want)
+	}
+	if got := anyOfMembers(testSchema(t, swagger, "Plain")); len(got) != 0 {
+		t.Errorf("anyOfMembers(Plain) = %v, want none", got)
+	}
+}
+
+func TestReferencedSchemas(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "refs", "version": "1"},
+		"paths": {"/pets": {"get": {
+			"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+		}}},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "properties": {"owner": {"$ref": "#/components/schemas/Owner"}}},
+			"Owner": {"type": "object"},
+			"Unused": {"type": "object"}
+		}}
+	}`, false)
+	if got, want := referencedSchemas(swagger), []string{"Owner", "Pet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("referencedSchemas() = %v, want %v", got, want)
+	}
+}
+
+func TestNumericType(t *testing.T) {
+	tests := []struct {
+		typ, format, want string
+	}{
+		{"integer", "", "int"},
+		{"integer", "int32", "int32"},
+		{"integer", "int64", "int64"},
+		{"integer", "uint8", "int"},
+		{"number", "", "float64"},
+		{"number", "float", "float32"},
+		{"number", "double", "float64"},
+		{"string", "int64", ""},
+		{"boolean", "", ""},
+	}
+	for _, tt := range tests {
+		schema := &openapi3.Schema{Type: tt.typ, Format: tt.format}
+		if got := numericType(schema); got != tt.want {
+			t.Errorf("numericType(%s/%s) = %q, want %q", tt.typ, tt.format, got, tt.want)
+		}
+	}
+	if got := numericType(nil); got != "" {
+		t.Errorf("numericType(nil) = %q, want empty", got)
+	}
+}
+
+func TestSchemaType(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "types", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Name": {"type": "string"},
+			"Count": {"type": "integer"},
+			"Pet": {"properties": {"name": {"type": "string"}}},
+			"Tags": {"items": {"type": "string"}},
+			"Anything": {},
+			"Ref": {"$ref": "#/components/schemas/Name"}
+		}}
+	}`, false)
+	tests := []struct {
+		name, want string
+	}{
+		{"Name", "string"},
+		{"Count", "integer"},
+		{"Pet", "object"},
+		{"Tags", "array"},
+		{"Anything", ""},
+		{"Ref", "string"},
+	}
+	for _, tt := range tests {
+		if got := schemaType(testSchema(t, swagger, tt.name)); got != tt.want {
+			t.Errorf("schemaType(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaTypeArrays(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.1.0",
+		"info": {"title": "types", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Name": {"type": ["string", "null"]},
+			"Count": {"type": ["null", "integer"], "format": "int64"},
+			"Plain": {"type": "string"},
+			"Pet": {"type": "object", "properties": {
+				"type": {"type": ["string", "null"], "example": {"type": ["kept"]}}
+			}}
+		}}
+	}`, false)
+	tests := []struct {
+		name, want string
+		nullable   bool
+	}{
+		{"Name", "string", true},
+		{"Count", "integer", true},
+		{"Plain", "string", false},
+	}
+	for _, tt := range tests {
+		schema := testSchema(t, swagger, tt.name)
+		if got := schemaType(schema); got != tt.want {
+			t.Errorf("schemaType(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+		if schema.Value.Nullable != tt.nullable {
+			t.Errorf("%s nullable = %v, want %v", tt.name, schema.Value.Nullable, tt.nullable)
+		}
+	}
+	if got := goType(testSchema(t, swagger, "Count")); got != "int64" {
+		t.Errorf("goType(Count) = %q, want int64", got)
+	}
+	petType := testSchema(t, swagger, "Pet").Value.Properties["type"]
+	if got := schemaType(petType); got != "string" {
+		t.Errorf("schemaType(Pet.type) = %q, want string", got)
+	}
+	if got, want := petType.Value.Example, map[string]interface{}{"type": []interface{}{"kept"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pet.type example = %v, want %v left untouched", got, want)
+	}
+}
+
+func TestIsEmptyObject(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "empty", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Empty": {"type": "object"},
+			"Closed": {"type": "object", "additionalProperties": false},
+			"Open": {"type": "object", "additionalProperties": true},
+			"Map": {"type": "object", "additionalProperties": {"type": "string"}},
+			"WithProps": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"Composed": {"type": "object", "allOf": [{"$ref": "#/components/schemas/WithProps"}]},
+			"Untyped": {},
+			"Ref": {"$ref": "#/components/schemas/Empty"}
+		}}
+	}`, false)
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Empty", true},
+		{"Closed", true},
+		{"Open", false},
+		{"Map", false},
+		{"WithProps", false},
+		{"Composed", false},
+		{"Untyped", false},
+		{"Ref", true},
+	}
+	for _, tt := range tests {
+		if got := isEmptyObject(testSchema(t, swagger, tt.name)); got != tt.want {
+			t.Errorf("isEmptyObject(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArrayItems(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "arrays", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"Pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}},
+			"Names": {"type": "array", "items": {"type": "string"}},
+			"Name": {"type": "string"}
+		}}
+	}`, false)
+	if got := arrayItems(testSchema(t, swagger, "Pets")); got != testSchema(t, swagger, "Pet").Value {
+		t.Errorf("arrayItems(Pets) = %+v, want the resolved Pet schema", got)
+	}
+	if got := arrayItems(testSchema(t, swagger, "Names")); got == nil || got.Type != "string" {
+		t.Errorf("arrayItems(Names) = %+v, want a string schema", got)
+	}
+	if got := arrayItems(testSchema(t, swagger, "Name")); got != nil {
+		t.Errorf("arrayItems(Name) = %+v, want nil", got)
+	}
+}
+
+func TestSchemaNameMap(t *testing.T) {
+	overrides, err := parseSchemaNameMap([]string{"user_dto=User"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old map[string]string) { schemaNameOverrides = old }(schemaNameOverrides)
+	schemaNameOverrides = overrides
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "renames", "version": "1"},
+		"paths": {"/accounts": {"get": {"responses": {"200": {"description": "ok", "content": {
+			"application/json": {"schema": {"$ref": "#/components/schemas/Account"}}
+		}}}}}},
+		"components": {"schemas": {
+			"user_dto": {"type": "object"},
+			"pet_dto": {"type": "object"},
+			"Account": {"type": "object", "properties": {
+				"owner": {"$ref": "#/components/schemas/user_dto"},
+				"members": {"type": "array", "items": {"$ref": "#/components/schemas/user_dto"}},
+				"pet": {"$ref": "#/components/schemas/pet_dto"}
+			}}
+		}}
+	}`, false)
+	props := testSchema(t, swagger, "Account").Value.Properties
+	tests := []struct {
+		field, want string
+	}{
+		{"owner", goName("user_dto")},
+		{"members", "[]" + goName("user_dto")},
+		{"pet", goName("pet_dto")},
+	}
+	for _, tt := range tests {
+		if got := goType(props[tt.field]); got != tt.want {
+			t.Errorf("goType(Account.%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+	if got := goName("user_dto"); got != "User" {
+		t.Errorf("goName(user_dto) = %q, want the User override", got)
+	}
+	if got := goName("pet_dto"); got != "PetDto" {
+		t.Errorf("goName(pet_dto) = %q, want PetDto", got)
+	}
+	// Overrides only apply to Go type names: spec names are kept, so they
+	// still index the components, and goName maps them to the type name.
+	owner := props["owner"].Ref
+	if got := refName(owner); got != "user_dto" {
+		t.Errorf("refName(%s) = %q, want the spec name user_dto", owner, got)
+	}
+	if got := goName(refName(owner)); got != goType(props["owner"]) {
+		t.Errorf("goName(refName(%s)) = %q, want the goType %q", owner, got, goType(props["owner"]))
+	}
+	if got, want := referencedSchemas(swagger), []string{"Account", "pet_dto", "user_dto"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("referencedSchemas() = %v, want %v", got, want)
+	}
+	if _, err := parseSchemaNameMap([]string{"user_dto"}); err == nil {
+		t.Error("parseSchemaNameMap accepted a mapping without =")
+	}
+}
+
+func TestGoTypeExtensions(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "overrides", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {
+			"ID": {"type": "string", "format": "uuid", "x-go-type": "uuid.UUID", "x-go-import": "github.com/google/uuid"},
+			"Money": {"type": "string", "x-go-type": "decimal.Decimal", "x-go-import": "github.com/shopspring/decimal"},
+			"Pet": {"type": "object", "properties": {
+				"id": {"$ref": "#/components/schemas/ID"},
+				"owners": {"type": "array", "items": {"$ref": "#/components/schemas/ID"}},
+				"name": {"type": "string"}
+			}},
+			"Plain": {"type": "object", "properties": {"name": {"type": "string"}}}
+		}}
+	}`, false)
+	pet := testSchema(t, swagger, "Pet")
+	tests := []struct {
+		field, want string
+	}{
+		{"id", "uuid.UUID"},
+		{"owners", "[]uuid.UUID"},
+		{"name", "string"},
+	}
+	for _, tt := range tests {
+		if got := goType(pet.Value.Properties[tt.field]); got != tt.want {
+			t.Errorf("goType(Pet.%s) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+	if got, want := goImports(swagger, pet), []string{"github.com/google/uuid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("goImports(Pet) = %v, want %v", got, want)
+	}
+	if got := goImports(swagger, testSchema(t, swagger, "Plain")); len(got) != 0 {
+		t.Errorf("goImports(Plain) = %v, want none", got)
+	}
+	if got, want := goImports(swagger), []string{"github.com/google/uuid", "github.com/shopspring/decimal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("goImports() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v2"
+)
+
+// loadedSpec is a parsed spec along with the output subdirectory its
+// templates render into.
+type loadedSpec struct {
+	name    string
+	swagger *openapi3.T
+	version string
+	dir     string
+}
+
+// loadSpecs loads the spec given by -spec-string or $OPENAPIGEN_SPEC, or
+// else every spec listed in -spec. When more than one spec is listed, each
+// renders into its own subdirectory named after its title, or failing that,
+// its filename.
+func loadSpecs() ([]loadedSpec, error) {
+	if raw, ok := inlineSpec(); ok {
+		swagger, version, err := parseSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+		return []loadedSpec{{name: "-spec-string", swagger: swagger, version: version}}, nil
+	}
+	fns := strings.Split(*spec, ",")
+	var specs []loadedSpec
+	dirs := make(map[string]string)
+	for _, fn := range fns {
+		s, err := loadSpecFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		if len(fns) > 1 {
+			s.dir = specDirName(s.swagger, s.name)
+			if other, ok := dirs[s.dir]; ok {
+				return nil, fmt.Errorf("%s and %s both render into %s", other, s.name, s.dir)
+			}
+			dirs[s.dir] = s.name
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+// loadSpecFile loads the spec stored in fn, or in the directory fn.
+func loadSpecFile(fn string) (loadedSpec, error) {
+	fn, err := discoverSpec(fn)
+	if err != nil {
+		return loadedSpec{}, err
+	}
+	raw, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return loadedSpec{}, fmt.Errorf("cannot open swagger json file: %w", err)
+	}
+	if strings.ToLower(filepath.Ext(fn)) == ".jsonc" {
+		raw = stripJSONC(raw)
+	}
+	swagger, version, err := parseSpec(raw)
+	if err != nil {
+		return loadedSpec{}, fmt.Errorf("%s: %w", fn, err)
+	}
+	return loadedSpec{name: fn, swagger: swagger, version: version}, nil
+}
+
+// specExtensions lists the file extensions considered by discoverSpec.
+var specExtensions = []string{".json", ".jsonc", ".yaml", ".yml"}
+
+// discoverSpec returns fn unchanged unless it is a directory, in which case
+// it returns the single spec file found directly inside it. Files that do not
+// declare an openapi or swagger version, like the -manifest and -report
+// outputs, are not considered.
+func discoverSpec(fn string) (string, error) {
+	fi, err := os.Stat(fn)
+	if err != nil || !fi.IsDir() {
+		return fn, nil
+	}
+	entries, err := ioutil.ReadDir(fn)
+	if err != nil {
+		return "", fmt.Errorf("cannot read spec directory: %w", err)
+	}
+	var found []string
+	for _, entry := range entries {
+		candidate := filepath.Join(fn, entry.Name())
+		if !entry.IsDir() && containsString(specExtensions, strings.ToLower(filepath.Ext(entry.Name()))) && isSpecFile(candidate) {
+			found = append(found, candidate)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no spec file (%s) found in %s", strings.Join(specExtensions, ", "), fn)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("more than one spec file found in %s: %s", fn, strings.Join(found, ", "))
+	}
+}
+
+// isSpecFile reports whether the JSON, JSONC or YAML file fn is a document
+// declaring an openapi or swagger version.
+func isSpecFile(fn string) bool {
+	raw, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return false
+	}
+	var doc map[string]interface{}
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".json", ".jsonc":
+		err = json.Unmarshal(stripJSONC(raw), &doc)
+	default:
+		err = yaml.Unmarshal(raw, &doc)
+	}
+	if err != nil {
+		return false
+	}
+	_, isOpenAPI := doc["openapi"]
+	_, isSwagger := doc["swagger"]
+	return isOpenAPI || isSwagger
+}
+
+// inlineSpec returns the raw spec from -spec-string or $OPENAPIGEN_SPEC, in
+// this order of precedence. $OPENAPIGEN_SPEC is ignored when -spec is given
+// explicitly.
+func inlineSpec() ([]byte, bool) {
+	if *specString != "" {
+		return []byte(*specString), true
+	}
+	if isFlagSet(flag.CommandLine, "spec") {
+		return nil, false
+	}
+	if env := os.Getenv("OPENAPIGEN_SPEC"); env != "" {
+		return []byte(env), true
+	}
+	return nil, false
+}
+
+// parseSpec decodes a raw spec according to -format and -v2mode. It also
+// returns the version the spec declares, which for v2 specs is lost in the
+// conversion to v3.
+func parseSpec(raw []byte) (*openapi3.T, string, error) {
+	if *expandEnv {
+		var err error
+		raw, err = expandSpecEnv(raw, *strictEnv)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	switch *specFormat {
+	case "json":
+	case "jsonc":
+		raw = stripJSONC(raw)
+	default:
+		return nil, "", fmt.Errorf("unknown spec format %q", *specFormat)
+	}
+	if *isOpenAPIV2 {
+		logInfo("Decoding spec file with https://godoc.org/github.com/getkin/kin-openapi/openapi2#T", "")
+		var swaggerV2 openapi2.T
+		err := json.Unmarshal(raw, &swaggerV2)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot parse swaggerV2 json file: %w", err)
+		}
+		preserveCollectionFormats(&swaggerV2)
+		swagger, err := openapi2conv.ToV3(&swaggerV2)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot convert from v2 to v3: %w", err)
+		}
+		normalizeSpec(swagger)
+		extractCollectionFormats(swagger)
+		return swagger, swaggerV2.Swagger, nil
+	}
+	raw, err := normalizeTypeArrays(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot normalize openAPI 3.1 types: %w", err)
+	}
+	swagger, err := openapi3.NewLoader().LoadFromData(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot parse openAPI v3 file: %w", err)
+	}
+	normalizeSpec(swagger)
+	return swagger, swagger.OpenAPI, nil
+}
+
+var envPlaceholderRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandSpecEnv substitutes ${NAME} placeholders in the raw spec with the
+// value of the matching environment variable. Unlike os.Expand, bare $NAME
+// forms are left alone so $ref keys survive. When strict is set, undefined
+// variables are an error; otherwise they expand to the empty string.
+func expandSpecEnv(raw []byte, strict bool) ([]byte, error) {
+	var missing []string
+	expanded := envPlaceholderRE.ReplaceAllFunc(raw, func(m []byte) []byte {
+		name := string(envPlaceholderRE.FindSubmatch(m)[1])
+		v, ok := os.LookupEnv(name)
+		if !ok && !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+		return []byte(v)
+	})
+	if strict && len(missing) > 0 {
+		return nil, fmt.Errorf("undefined environment variables in spec: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// normalizeSpec gives specs without paths or components, such as pure
+// component libraries, empty ones, so templates and helpers can walk them
+// without nil checks.
+func normalizeSpec(swagger *openapi3.T) {
+	if swagger.Paths == nil {
+		swagger.Paths = openapi3.Paths{}
+	}
+	if swagger.Components == nil {
+		swagger.Components = &openapi3.Components{}
+	}
+}
+
+// normalizeTypeArrays rewrites the type arrays of an OpenAPI 3.1 spec, such
+// as ["string", "null"], into the single type the loader understands: the
+// first non-null type, with nullable set when null is allowed. Other specs,
+// and documents that are not JSON, are returned unchanged.
+func normalizeTypeArrays(raw []byte) ([]byte, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return raw, nil
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return raw, nil
+	}
+	if version, _ := root["openapi"].(string); !isSpecVersion(version, "3.1") {
+		return raw, nil
+	}
+	if !rewriteTypeArrays(doc) {
+		return raw, nil
+	}
+	return json.Marshal(doc)
+}
+
+// literalKeys name the spec fields holding literal values rather than
+// schemas, which rewriteTypeArrays leaves alone.
+var literalKeys = map[string]bool{
+	"example": true, "examples": true, "default": true, "enum": true, "const": true,
+}
+
+// rewriteTypeArrays replaces, in place, every type array found in v with its
+// first non-null type. It reports whether anything changed.
+func rewriteTypeArrays(v interface{}) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if types, ok := v["type"].([]interface{}); ok {
+			var primary string
+			nullable := false
+			for _, t := range types {
+				switch name, _ := t.(string); {
+				case name == "null":
+					nullable = true
+				case primary == "":
+					primary = name
+				}
+			}
+			delete(v, "type")
+			if primary != "" {
+				v["type"] = primary
+			}
+			if nullable {
+				v["nullable"] = true
+			}
+			changed = true
+		}
+		for key, child := range v {
+			if !literalKeys[key] {
+				changed = rewriteTypeArrays(child) || changed
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			changed = rewriteTypeArrays(child) || changed
+		}
+	}
+	return changed
+}
+
+// collectionFormatExtension carries the v2 collectionFormat of a parameter
+// through the conversion to v3, which otherwise drops it.
+const collectionFormatExtension = "x-collection-format"
+
+// collectionFormats maps the parameters of converted v2 specs to their
+// collectionFormat. It is kept aside, rather than in the parameter
+// extensions, so it does not leak into -view, toJSON, operationHash or -diff.
+var collectionFormats = make(map[*openapi3.Parameter]string)
+
+// preserveCollectionFormats copies the collectionFormat of every v2
+// parameter into its collectionFormatExtension, for extractCollectionFormats
+// to pick up after the conversion. formData parameters are skipped: they
+// become request body properties, not v3 parameters.
+func preserveCollectionFormats(doc *openapi2.T) {
+	preserve := func(param *openapi2.Parameter) {
+		if param == nil || param.CollectionFormat == "" || param.In == "formData" {
+			return
+		}
+		if param.Extensions == nil {
+			param.Extensions = make(map[string]interface{})
+		}
+		param.Extensions[collectionFormatExtension] = param.CollectionFormat
+	}
+	for _, param := range doc.Parameters {
+		preserve(param)
+	}
+	for _, pathItem := range doc.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, param := range pathItem.Parameters {
+			preserve(param)
+		}
+		for _, op := range pathItem.Operations() {
+			for _, param := range op.Parameters {
+				preserve(param)
+			}
+		}
+	}
+}
+
+// extractCollectionFormats moves the collectionFormatExtension of every
+// parameter of the converted spec into collectionFormats.
+func extractCollectionFormats(swagger *openapi3.T) {
+	extract := func(params openapi3.Parameters) {
+		for _, ref := range params {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			if format := extensionString(ref.Value.Extensions, collectionFormatExtension); format != "" {
+				collectionFormats[ref.Value] = format
+			}
+			delete(ref.Value.Extensions, collectionFormatExtension)
+		}
+	}
+	for _, ref := range swagger.Components.Parameters {
+		extract(openapi3.Parameters{ref})
+	}
+	for _, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		extract(pathItem.Parameters)
+		for _, op := range pathItem.Operations() {
+			extract(op.Parameters)
+		}
+	}
+}
+
+// isSpecVersion reports whether version falls under the given major, or
+// major.minor, version prefix.
+func isSpecVersion(version, prefix string) bool {
+	return version == prefix || strings.HasPrefix(version, prefix+".")
+}
+
+// specDirName names the output subdirectory of a spec after its title, or
+// its filename when it has none.
+func specDirName(swagger *openapi3.T, fn string) string {
+	if swagger != nil && swagger.Info != nil && swagger.Info.Title != "" {
+		return toSnake(swagger.Info.Title)
+	}
+	return strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+}
@@ -0,0 +1,220 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSpecEnvPrecedence(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         petstoreSpec,
+		"tpl/title.txt.tpl": "{{ .Info.Title }}",
+	})
+	env := []string{"OPENAPIGEN_SPEC=" + strings.Replace(petstoreSpec, "Pet Store", "From Env", 1)}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"explicit -spec", []string{"-spec", "spec.json"}, "Pet Store"},
+		{"omitted -spec", nil, "From Env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(dir, strings.Replace(tt.name, " ", "_", -1))
+			args := append([]string{"-template", "tpl", "-output", output}, tt.args...)
+			if out, code := runOpenapigen(t, dir, env, args...); code != 0 {
+				t.Fatalf("openapigen failed: %s", out)
+			}
+			if got := readTestFile(t, filepath.Join(output, "title.txt")); got != tt.want {
+				t.Errorf("rendered title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSpecsIntoSubdirectories(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"pets.json":         petstoreSpec,
+		"untitled.json":     strings.Replace(petstoreSpec, "Pet Store", "", 1),
+		"tpl/title.txt.tpl": "[{{ .Info.Title }}]",
+	})
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "pets.json,untitled.json", "-template", "tpl", "-output", "out"); code != 0 {
+		t.Fatalf("openapigen failed: %s", out)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "pet_store", "title.txt")); got != "[Pet Store]" {
+		t.Errorf("pet_store/title.txt = %q, want [Pet Store]", got)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "out", "untitled", "title.txt")); got != "[]" {
+		t.Errorf("untitled/title.txt = %q, want []", got)
+	}
+	if out, code := runOpenapigen(t, dir, nil, "-spec", "pets.json,pets.json", "-template", "tpl", "-output", "out"); code == 0 {
+		t.Errorf("specs rendering into the same subdirectory should fail: %s", out)
+	}
+}
+
+func TestParseSpecVersion(t *testing.T) {
+	tests := []struct {
+		name              string
+		raw               string
+		v2                bool
+		want              string
+		isV2, isV3, isV31 bool
+	}{
+		{"v2", `{"swagger": "2.0", "info": {"title": "v2", "version": "1"}, "paths": {}}`, true, "2.0", true, false, false},
+		{"v3", petstoreSpec, false, "3.0.0", false, true, false},
+		{"v3.1", strings.Replace(petstoreSpec, `"3.0.0"`, `"3.1.0"`, 1), false, "3.1.0", false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(old bool) { *isOpenAPIV2 = old }(*isOpenAPIV2)
+			*isOpenAPIV2 = tt.v2
+			_, version, err := parseSpec([]byte(tt.raw))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != tt.want {
+				t.Errorf("version = %q, want %q", version, tt.want)
+			}
+			if got := isSpecVersion(version, "2"); got != tt.isV2 {
+				t.Errorf("isV2 = %v, want %v", got, tt.isV2)
+			}
+			if got := isSpecVersion(version, "3"); got != tt.isV3 {
+				t.Errorf("isV3 = %v, want %v", got, tt.isV3)
+			}
+			if got := isSpecVersion(version, "3.1"); got != tt.isV31 {
+				t.Errorf("isV31 = %v, want %v", got, tt.isV31)
+			}
+		})
+	}
+	if isSpecVersion("3.10.0", "3.1") {
+		t.Error("3.10.0 must not match 3.1")
+	}
+}
+
+func TestDiscoverSpec(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"single/openapi.json":   petstoreSpec,
+		"single/README.md":      "not a spec",
+		"single/sub/x.json":     "{}",
+		"many/a.json":           petstoreSpec,
+		"many/b.yaml":           "openapi: 3.0.0",
+		"none/README.md":        "not a spec",
+		"outputs/spec.jsonc":    "// the pet store\n" + petstoreSpec,
+		"outputs/report.json":   `{"templatesRendered": 1}`,
+		"outputs/manifest.json": `{"files": []}`,
+		"outputs/values.yaml":   "replicas: 2",
+	})
+	got, err := discoverSpec(filepath.Join(dir, "single"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "single", "openapi.json"); got != want {
+		t.Errorf("discoverSpec(single) = %q, want %q", got, want)
+	}
+	file := filepath.Join(dir, "many", "a.json")
+	if got, err := discoverSpec(file); err != nil || got != file {
+		t.Errorf("discoverSpec(file) = %q, %v; want the file itself", got, err)
+	}
+	for _, name := range []string{"many", "none"} {
+		if _, err := discoverSpec(filepath.Join(dir, name)); err == nil {
+			t.Errorf("discoverSpec(%s) should fail", name)
+		}
+	}
+	got, err = discoverSpec(filepath.Join(dir, "outputs"))
+	if err != nil {
+		t.Fatalf("discoverSpec(outputs) failed: %v", err)
+	}
+	if want := filepath.Join(dir, "outputs", "spec.jsonc"); got != want {
+		t.Errorf("discoverSpec(outputs) = %q, want %q", got, want)
+	}
+	if s, err := loadSpecFile(filepath.Join(dir, "outputs")); err != nil {
+		t.Errorf("loadSpecFile(outputs) failed to strip the comments of a .jsonc spec: %v", err)
+	} else if s.swagger.Info.Title != "Pet Store" {
+		t.Errorf("loadSpecFile(outputs) loaded %q, want Pet Store", s.swagger.Info.Title)
+	}
+	s, err := loadSpecFile(filepath.Join(dir, "single"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.swagger.Info.Title != "Pet Store" {
+		t.Errorf("loadSpecFile(single) loaded %q, want Pet Store", s.swagger.Info.Title)
+	}
+}
+
+func TestExpandSpecEnv(t *testing.T) {
+	defer os.Unsetenv("OPENAPIGEN_TEST_HOST")
+	os.Setenv("OPENAPIGEN_TEST_HOST", "api.example.com")
+	os.Unsetenv("OPENAPIGEN_TEST_UNSET")
+	raw := `{"url": "https://${OPENAPIGEN_TEST_HOST}/v1", "$ref": "#/x", "cost": "$OPENAPIGEN_TEST_HOST", "missing": "${OPENAPIGEN_TEST_UNSET}"}`
+	got, err := expandSpecEnv([]byte(raw), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"url": "https://api.example.com/v1", "$ref": "#/x", "cost": "$OPENAPIGEN_TEST_HOST", "missing": ""}`
+	if string(got) != want {
+		t.Errorf("expandSpecEnv() = %s, want %s", got, want)
+	}
+	_, err = expandSpecEnv([]byte(raw), true)
+	if err == nil || !strings.Contains(err.Error(), "OPENAPIGEN_TEST_UNSET") {
+		t.Errorf("strict expandSpecEnv() = %v, want an error naming OPENAPIGEN_TEST_UNSET", err)
+	}
+}
+
+func TestComponentLibrarySpec(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"models.json": `{
+			"openapi": "3.0.0",
+			"info": {"title": "Models", "version": "1"},
+			"components": {"schemas": {
+				"Pet": {"type": "object", "properties": {"owner": {"$ref": "#/components/schemas/Owner"}}},
+				"Owner": {"type": "object"}
+			}}
+		}`,
+		"empty.json":             `{"openapi": "3.0.0", "info": {"title": "Empty", "version": "1"}}`,
+		"tpl/models.go.tpl":      "{{ range sortedSchemas }}type {{ goName .Name }} struct{}\n{{ end }}",
+		"tpl/operations.txt.tpl": "{{ len .Paths }} paths{{ range $path, $item := .Paths }} {{ $path }}{{ end }}",
+	})
+	tests := []struct {
+		spec, models string
+	}{
+		{"models.json", "type Owner struct{}\ntype Pet struct{}\n"},
+		{"empty.json", ""},
+	}
+	for _, tt := range tests {
+		out := strings.TrimSuffix(tt.spec, ".json")
+		if log, code := runOpenapigen(t, dir, nil, "-spec", tt.spec, "-template", "tpl", "-output", out); code != 0 {
+			t.Fatalf("rendering %s failed: %s", tt.spec, log)
+		}
+		if got := readTestFile(t, filepath.Join(dir, out, "models.go")); got != tt.models {
+			t.Errorf("%s: models.go = %q, want %q", tt.spec, got, tt.models)
+		}
+		if got := readTestFile(t, filepath.Join(dir, out, "operations.txt")); got != "0 paths" {
+			t.Errorf("%s: operations.txt = %q, want 0 paths", tt.spec, got)
+		}
+	}
+}
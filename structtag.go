@@ -0,0 +1,152 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// structTagSets lists the tag sets structTag knows how to emit.
+var structTagSets = []string{"json", "validate"}
+
+// parseStructTagSets parses the comma-separated list given to -tags.
+func parseStructTagSets(s string) ([]string, error) {
+	var sets []string
+	for _, set := range strings.Split(s, ",") {
+		set = strings.TrimSpace(set)
+		if set == "" {
+			continue
+		}
+		if !containsString(structTagSets, set) {
+			return nil, fmt.Errorf("unknown struct tag set %q (expected one of %s)", set, strings.Join(structTagSets, ", "))
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// structTag renders, backquoted, the Go struct tag of the property propName
+// of the object schema v, emitting the given tag sets.
+func structTag(sets []string, v interface{}, propName string) string {
+	schema := schemaOf(v)
+	if schema == nil {
+		return ""
+	}
+	required := containsString(schema.Required, propName)
+	var tags []string
+	for _, set := range sets {
+		switch set {
+		case "json":
+			tag := propName
+			if !required {
+				tag += ",omitempty"
+			}
+			tags = append(tags, fmt.Sprintf("json:%q", tag))
+		case "validate":
+			rules := validateRules(schema.Properties[propName])
+			if required {
+				rules = append([]string{"required"}, rules...)
+			} else if len(rules) > 0 {
+				rules = append([]string{"omitempty"}, rules...)
+			}
+			if len(rules) > 0 {
+				tags = append(tags, fmt.Sprintf("validate:%q", strings.Join(rules, ",")))
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// validateFormats maps OpenAPI string formats to their validator rule.
+var validateFormats = map[string]string{
+	"email":    "email",
+	"uuid":     "uuid",
+	"uri":      "uri",
+	"url":      "url",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+}
+
+// validateRules returns the go-playground/validator rules matching the
+// format and constraints of the schema.
+func validateRules(v interface{}) []string {
+	schema := schemaOf(v)
+	if schema == nil {
+		return nil
+	}
+	var rules []string
+	if rule, ok := validateFormats[schema.Format]; ok {
+		rules = append(rules, rule)
+	}
+	c := constraints(schema)
+	if c.MinLength != nil {
+		rules = append(rules, "min="+strconv.FormatUint(*c.MinLength, 10))
+	}
+	if c.MaxLength != nil {
+		rules = append(rules, "max="+strconv.FormatUint(*c.MaxLength, 10))
+	}
+	if c.MinItems != nil {
+		rules = append(rules, "min="+strconv.FormatUint(*c.MinItems, 10))
+	}
+	if c.MaxItems != nil {
+		rules = append(rules, "max="+strconv.FormatUint(*c.MaxItems, 10))
+	}
+	if c.Minimum != nil {
+		op := "gte="
+		if c.ExclusiveMinimum {
+			op = "gt="
+		}
+		rules = append(rules, op+strconv.FormatFloat(*c.Minimum, 'f', -1, 64))
+	}
+	if c.Maximum != nil {
+		op := "lte="
+		if c.ExclusiveMaximum {
+			op = "lt="
+		}
+		rules = append(rules, op+strconv.FormatFloat(*c.Maximum, 'f', -1, 64))
+	}
+	if oneOf := validateOneOf(schema.Enum); oneOf != "" {
+		rules = append(rules, "oneof="+oneOf)
+	}
+	return rules
+}
+
+// validateOneOf renders the enum as the space-separated list of the oneof
+// rule, or returns an empty string when a value cannot be expressed in it.
+func validateOneOf(enum []interface{}) string {
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		var s string
+		switch e := e.(type) {
+		case string:
+			s = e
+		case float64:
+			s = strconv.FormatFloat(e, 'f', -1, 64)
+		default:
+			return ""
+		}
+		if s == "" || strings.ContainsAny(s, " ,|") {
+			return ""
+		}
+		values = append(values, s)
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestStructTag(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "tags", "version": "1"},
+		"paths": {},
+		"components": {"schemas": {"User": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {
+				"email": {"type": "string", "format": "email"},
+				"nickname": {"type": "string", "maxLength": 20},
+				"bio": {"type": "string"}
+			}
+		}}}
+	}`, false)
+	user := testSchema(t, swagger, "User")
+	tests := []struct {
+		prop string
+		sets []string
+		want string
+	}{
+		{"email", []string{"json"}, "`json:\"email\"`"},
+		{"nickname", []string{"json"}, "`json:\"nickname,omitempty\"`"},
+		{"email", []string{"json", "validate"}, "`json:\"email\" validate:\"required,email\"`"},
+		{"nickname", []string{"json", "validate"}, "`json:\"nickname,omitempty\" validate:\"omitempty,max=20\"`"},
+		{"bio", []string{"json", "validate"}, "`json:\"bio,omitempty\"`"},
+		{"bio", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := structTag(tt.sets, user, tt.prop); got != tt.want {
+			t.Errorf("structTag(%v, %s) = %s, want %s", tt.sets, tt.prop, got, tt.want)
+		}
+	}
+	if _, err := parseStructTagSets("json,xml"); err == nil {
+		t.Error("parseStructTagSets should reject unknown sets")
+	}
+}
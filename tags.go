@@ -0,0 +1,141 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// uniquePathTags lists, sorted, the tags used by the operations in the spec.
+func uniquePathTags(swagger *openapi3.T) []string {
+	var tags []string
+	for _, pathItem := range swagger.Paths {
+		if pathItem.Connect != nil {
+			tags = append(tags, pathItem.Connect.Tags...)
+		}
+		if pathItem.Delete != nil {
+			tags = append(tags, pathItem.Delete.Tags...)
+		}
+		if pathItem.Get != nil {
+			tags = append(tags, pathItem.Get.Tags...)
+		}
+		if pathItem.Head != nil {
+			tags = append(tags, pathItem.Head.Tags...)
+		}
+		if pathItem.Options != nil {
+			tags = append(tags, pathItem.Options.Tags...)
+		}
+		if pathItem.Patch != nil {
+			tags = append(tags, pathItem.Patch.Tags...)
+		}
+		if pathItem.Post != nil {
+			tags = append(tags, pathItem.Post.Tags...)
+		}
+		if pathItem.Put != nil {
+			tags = append(tags, pathItem.Put.Tags...)
+		}
+		if pathItem.Trace != nil {
+			tags = append(tags, pathItem.Trace.Tags...)
+		}
+	}
+	tagsDict := make(map[string]struct{})
+	for _, tag := range tags {
+		tagsDict[tag] = struct{}{}
+	}
+	uniqTags := []string{}
+	for tag := range tagsDict {
+		uniqTags = append(uniqTags, tag)
+	}
+	sort.Strings(uniqTags)
+	return uniqTags
+}
+
+// tagInfo returns the tag object declared with the given name. Tags used by
+// operations but not declared at the top level yield a bare tag carrying only
+// its name.
+func tagInfo(swagger *openapi3.T, name string) *openapi3.Tag {
+	if tag := swagger.Tags.Get(name); tag != nil {
+		return tag
+	}
+	return &openapi3.Tag{Name: name}
+}
+
+// sortedTags lists the declared tags in declaration order, followed by the
+// undeclared tags used by operations in alphabetical order.
+func sortedTags(swagger *openapi3.T) []*openapi3.Tag {
+	tags := []*openapi3.Tag{}
+	for _, tag := range swagger.Tags {
+		if tag != nil {
+			tags = append(tags, tag)
+		}
+	}
+	for _, name := range uniquePathTags(swagger) {
+		if swagger.Tags.Get(name) == nil {
+			tags = append(tags, &openapi3.Tag{Name: name})
+		}
+	}
+	return tags
+}
+
+// operationTags returns the tags of the operation, never nil.
+func operationTags(operation *openapi3.Operation) []string {
+	if operation == nil || operation.Tags == nil {
+		return []string{}
+	}
+	return operation.Tags
+}
+
+// primaryTag returns the first tag of the operation. Untagged operations
+// yield the optional fallback, or the empty string.
+func primaryTag(operation *openapi3.Operation, fallback ...string) string {
+	if tags := operationTags(operation); len(tags) > 0 {
+		return tags[0]
+	}
+	if len(fallback) > 0 {
+		return fallback[0]
+	}
+	return ""
+}
+
+// filterByTag drops from the spec every operation not tagged with tag, the
+// paths left without operations and the declared tags other than tag.
+// Component schemas are kept intact.
+func filterByTag(swagger *openapi3.T, tag string) {
+	if swagger == nil {
+		return
+	}
+	for path, pathItem := range swagger.Paths {
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			if op := pathItem.GetOperation(method); op != nil && !containsString(op.Tags, tag) {
+				pathItem.SetOperation(method, nil)
+			}
+		}
+		if len(pathItem.Operations()) == 0 {
+			delete(swagger.Paths, path)
+		}
+	}
+	var tags openapi3.Tags
+	for _, t := range swagger.Tags {
+		if t != nil && t.Name == tag {
+			tags = append(tags, t)
+		}
+	}
+	swagger.Tags = tags
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestTagInfo(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "tags", "version": "1"},
+		"tags": [{"name": "pets", "description": "Everything about pets"}],
+		"paths": {"/stores": {"get": {"tags": ["stores"], "responses": {"200": {"description": "ok"}}}}}
+	}`, false)
+	if got := tagInfo(swagger, "pets").Description; got != "Everything about pets" {
+		t.Errorf("tagInfo(pets).Description = %q, want the declared description", got)
+	}
+	undeclared := tagInfo(swagger, "stores")
+	if undeclared.Name != "stores" || undeclared.Description != "" {
+		t.Errorf("tagInfo(stores) = %+v, want a bare tag", undeclared)
+	}
+}
+
+func TestPrimaryTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation *openapi3.Operation
+		fallback  []string
+		want      string
+	}{
+		{"multiple tags", &openapi3.Operation{Tags: []string{"pets", "store"}}, nil, "pets"},
+		{"single tag", &openapi3.Operation{Tags: []string{"store"}}, []string{"default"}, "store"},
+		{"untagged", &openapi3.Operation{}, nil, ""},
+		{"untagged with fallback", &openapi3.Operation{}, []string{"default"}, "default"},
+		{"nil operation", nil, []string{"default"}, "default"},
+	}
+	for _, tt := range tests {
+		if got := primaryTag(tt.operation, tt.fallback...); got != tt.want {
+			t.Errorf("%s: primaryTag() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	swagger := parseTestSpec(t, `{
+		"openapi": "3.0.0",
+		"info": {"title": "tags", "version": "1"},
+		"tags": [{"name": "pets"}, {"name": "stores"}],
+		"paths": {
+			"/pets": {
+				"get": {"tags": ["pets"], "responses": {"200": {"description": "ok"}}},
+				"delete": {"tags": ["admin"], "responses": {"204": {"description": "ok"}}}
+			},
+			"/stores": {"get": {"tags": ["stores"], "responses": {"200": {"description": "ok"}}}},
+			"/both": {"get": {"tags": ["stores", "pets"], "responses": {"200": {"description": "ok"}}}}
+		},
+		"components": {"schemas": {"Store": {"type": "object"}}}
+	}`, false)
+	filterByTag(swagger, "pets")
+	var paths []string
+	for path := range swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if want := []string{"/both", "/pets"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if pets := swagger.Paths["/pets"]; pets != nil && (pets.Get == nil || pets.Delete != nil) {
+		t.Errorf("/pets keeps GET %v and DELETE %v, want only GET", pets.Get != nil, pets.Delete != nil)
+	}
+	if len(swagger.Tags) != 1 || swagger.Tags[0].Name != "pets" {
+		t.Errorf("declared tags = %+v, want only pets", swagger.Tags)
+	}
+	if _, ok := swagger.Components.Schemas["Store"]; !ok {
+		t.Error("filterByTag dropped a component schema")
+	}
+}
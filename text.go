@@ -0,0 +1,253 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// splitWords splits an identifier into words at case changes and at every
+// rune that is neither a letter nor a digit, which is dropped. Runs of
+// uppercase letters are kept together as acronyms, so "userID" yields
+// "user", "ID" and "HTTPServer" yields "HTTP", "Server". A plural "s" stays
+// with the initialism before it, so "getURLs" yields "get", "URLs".
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			words = append(words, string(runes[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !isPluralInitialism(runes[start:], i+1-start):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+// isPluralInitialism reports whether runes[:n] is a common initialism
+// followed by a plural "s" that ends the word, as in "URLs" or "IDsByName".
+func isPluralInitialism(runes []rune, n int) bool {
+	if !commonInitialisms[string(runes[:n])] || n >= len(runes) || runes[n] != 's' {
+		return false
+	}
+	return n+1 == len(runes) || !unicode.IsLower(runes[n+1])
+}
+
+// capitalize uppercases the first letter of word.
+func capitalize(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// isAcronym reports whether every letter of word is uppercase.
+func isAcronym(word string) bool {
+	hasLetter := false
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+			if !unicode.IsUpper(r) {
+				return false
+			}
+		}
+	}
+	return hasLetter && len([]rune(word)) > 1
+}
+
+// commonInitialisms lists the words that toCamel and toLowerCamel render
+// in uppercase, following the Go naming conventions.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true,
+	"SMTP": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "UUID": true,
+	"URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+	"XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// The casing helpers below share identifierWords, so identifiers keep the same
+// words through any chain of transformations: snake turns "UserID" into
+// "user_id", and camel turns "user_id" back into "UserID". Initialisms are
+// uppercased as a whole in camel case and lowercased as a whole in snake
+// case; other words are capitalized.
+
+// identifierWords splits s as splitWords does, further separating runs of
+// uppercase letters made of adjacent initialisms, so "XMLHTTPRequest" yields
+// "XML", "HTTP", "Request".
+func identifierWords(s string) []string {
+	var words []string
+	for _, word := range splitWords(s) {
+		words = append(words, splitInitialisms(word)...)
+	}
+	return words
+}
+
+// splitInitialisms splits word into the initialisms it is made of, or
+// returns it whole when it is not entirely made of initialisms.
+func splitInitialisms(word string) []string {
+	if !isAcronym(word) || commonInitialisms[word] {
+		return []string{word}
+	}
+	for i := len(word) - 1; i > 0; i-- {
+		if !commonInitialisms[word[:i]] {
+			continue
+		}
+		if rest := splitInitialisms(word[i:]); len(rest) > 1 || commonInitialisms[rest[0]] {
+			return append([]string{word[:i]}, rest...)
+		}
+	}
+	return []string{word}
+}
+
+// initialismCase renders word in the case of the initialism it is made of,
+// such as "URL" for "url", "URLs" for "urls" and "HTTP2" for "http2". It
+// reports false when word is not an initialism.
+func initialismCase(word string) (string, bool) {
+	upper := strings.ToUpper(word)
+	if commonInitialisms[upper] {
+		return upper, true
+	}
+	if stem := strings.TrimRight(upper, "0123456789"); stem != upper && commonInitialisms[stem] {
+		return upper, true
+	}
+	if stem := strings.TrimSuffix(upper, "S"); stem != upper && commonInitialisms[stem] && strings.HasSuffix(word, "s") {
+		return stem + "s", true
+	}
+	return "", false
+}
+
+// toCamel renders s in UpperCamelCase, e.g. "user_id" becomes "UserID".
+// Runes that cannot appear in a Go identifier are dropped.
+func toCamel(s string) string {
+	words := identifierWords(s)
+	for i, word := range words {
+		if initialism, ok := initialismCase(word); ok {
+			words[i] = initialism
+			continue
+		}
+		words[i] = capitalize(strings.ToLower(word))
+	}
+	return strings.Join(words, "")
+}
+
+// toLowerCamel renders s in lowerCamelCase, e.g. "ID_token" becomes
+// "idToken" and "user_id" becomes "userID".
+func toLowerCamel(s string) string {
+	words := identifierWords(s)
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.ToLower(words[0]) + toCamel(strings.Join(words[1:], "_"))
+}
+
+// toSnake renders s in snake_case, e.g. "UserID" becomes "user_id".
+func toSnake(s string) string {
+	words := identifierWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// humanize turns an identifier into a human-facing label, e.g. "firstName"
+// and "first_name" become "First Name". Initialisms are uppercased, as in
+// toCamel, so "userID" and "user_id" both become "User ID".
+func humanize(s string) string {
+	words := identifierWords(s)
+	for i, word := range words {
+		if initialism, ok := initialismCase(word); ok {
+			words[i] = initialism
+			continue
+		}
+		if isAcronym(word) {
+			continue
+		}
+		words[i] = capitalize(strings.ToLower(word))
+	}
+	return strings.Join(words, " ")
+}
+
+// title uppercases the first letter of every space-separated word.
+func title(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// goString renders s as a double-quoted Go string literal.
+func goString(s string) string {
+	return strconv.Quote(s)
+}
+
+// goRawString renders s as a backquoted Go raw string literal, falling back
+// to a double-quoted literal when s holds characters a raw string cannot
+// represent: backquotes, carriage returns and invalid UTF-8.
+func goRawString(s string) string {
+	if strconv.CanBackquote(strings.Replace(s, "\n", "", -1)) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// oneLine collapses every run of whitespace in s, newlines included, into a
+// single space and trims the result, so it fits a single-line comment.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// base64Encode encodes s with the standard base64 encoding.
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// base64Decode decodes s from the standard base64 encoding.
+func base64Decode(s string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode base64: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go/token"
+	"testing"
+)
+
+func TestBase64RoundTrip(t *testing.T) {
+	for _, s := range []string{"", "hello", "héllo, wörld\n", "\x00\xff"} {
+		encoded := base64Encode(s)
+		decoded, err := base64Decode(encoded)
+		if err != nil {
+			t.Errorf("base64Decode(%q) failed: %v", encoded, err)
+			continue
+		}
+		if decoded != s {
+			t.Errorf("round trip of %q yielded %q", s, decoded)
+		}
+	}
+	if got := base64Encode("hello"); got != "aGVsbG8=" {
+		t.Errorf("base64Encode(hello) = %q, want aGVsbG8=", got)
+	}
+	for _, invalid := range []string{"not base64!", "aGVsbG8", "===="} {
+		if _, err := base64Decode(invalid); err == nil {
+			t.Errorf("base64Decode(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"firstName", "First Name"},
+		{"first_name", "First Name"},
+		{"FirstName", "First Name"},
+		{"first-name", "First Name"},
+		{"userID", "User ID"},
+		{"user_id", "User ID"},
+		{"HTTPServer", "HTTP Server"},
+		{"api_key", "API Key"},
+		{"NASA_mission", "NASA Mission"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := humanize(tt.in); got != tt.want {
+			t.Errorf("humanize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"pet store", "Pet Store"},
+		{"the HTTP  api", "The HTTP Api"},
+		{"éclair shop", "Éclair Shop"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := title(tt.in); got != tt.want {
+			t.Errorf("title(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGoStringLiterals(t *testing.T) {
+	tests := []struct {
+		in, quoted, raw string
+	}{
+		{"plain", `"plain"`, "`plain`"},
+		{`say "hi"`, `"say \"hi\""`, "`say \"hi\"`"},
+		{`C:\path`, `"C:\\path"`, "`C:\\path`"},
+		{"two\nlines", `"two\nlines"`, "`two\nlines`"},
+		{"back`quote", "\"back`quote\"", "\"back`quote\""},
+		{"cr\r\n", `"cr\r\n"`, `"cr\r\n"`},
+	}
+	for _, tt := range tests {
+		if got := goString(tt.in); got != tt.quoted {
+			t.Errorf("goString(%q) = %s, want %s", tt.in, got, tt.quoted)
+		}
+		if got := goRawString(tt.in); got != tt.raw {
+			t.Errorf("goRawString(%q) = %s, want %s", tt.in, got, tt.raw)
+		}
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Lists the pets.", "Lists the pets."},
+		{"Lists the pets\nin the store.\n", "Lists the pets in the store."},
+		{"  Lists\r\n\tthe   pets.\n\n", "Lists the pets."},
+		{"\n", ""},
+	}
+	for _, tt := range tests {
+		if got := oneLine(tt.in); got != tt.want {
+			t.Errorf("oneLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCasing(t *testing.T) {
+	tests := []struct {
+		in, camel, lowerCamel, snake string
+	}{
+		{"user_id", "UserID", "userID", "user_id"},
+		{"UserID", "UserID", "userID", "user_id"},
+		{"XMLHTTPRequest", "XMLHTTPRequest", "xmlHTTPRequest", "xml_http_request"},
+		{"getURLs", "GetURLs", "getURLs", "get_urls"},
+		{"IDs", "IDs", "ids", "ids"},
+		{"userIDsByName", "UserIDsByName", "userIDsByName", "user_ids_by_name"},
+		{"HTTP2Server", "HTTP2Server", "http2Server", "http2_server"},
+		{"HTTPServer", "HTTPServer", "httpServer", "http_server"},
+		{"HTTPStatus", "HTTPStatus", "httpStatus", "http_status"},
+		{"foo$bar", "FooBar", "fooBar", "foo_bar"},
+		{"pets/{petId}", "PetsPetID", "petsPetID", "pets_pet_id"},
+		{"Response«Pet»", "ResponsePet", "responsePet", "response_pet"},
+		{"Pet Store (v2)", "PetStoreV2", "petStoreV2", "pet_store_v2"},
+	}
+	for _, tt := range tests {
+		if got := toCamel(tt.in); got != tt.camel {
+			t.Errorf("toCamel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := toLowerCamel(tt.in); got != tt.lowerCamel {
+			t.Errorf("toLowerCamel(%q) = %q, want %q", tt.in, got, tt.lowerCamel)
+		}
+		if got := toSnake(tt.in); got != tt.snake {
+			t.Errorf("toSnake(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := toCamel(toSnake(tt.in)); got != tt.camel {
+			t.Errorf("toCamel(toSnake(%q)) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := toSnake(toCamel(tt.in)); got != tt.snake {
+			t.Errorf("toSnake(toCamel(%q)) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if !token.IsIdentifier(toCamel(tt.in)) || !token.IsIdentifier(toLowerCamel(tt.in)) {
+			t.Errorf("camel cases of %q are not valid Go identifiers", tt.in)
+		}
+	}
+}
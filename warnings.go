@@ -0,0 +1,94 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// specWarnings lists the non-fatal issues found in the spec: problems
+// reported by kin-openapi's validation and constructs that usually produce
+// poor generated code.
+func specWarnings(swagger *openapi3.T) []string {
+	if swagger == nil {
+		return nil
+	}
+	var warnings []string
+	if err := swagger.Validate(context.Background()); err != nil {
+		warnings = append(warnings, fmt.Sprintf("spec validation: %v", err))
+	}
+	paths := make([]string, 0, len(swagger.Paths))
+	for path := range swagger.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	operationIDs := make(map[string]string)
+	for _, path := range paths {
+		pathItem := swagger.Paths[path]
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range httpMethods {
+			op := pathItem.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			where := method + " " + path
+			if op.OperationID == "" {
+				warnings = append(warnings, fmt.Sprintf("%s: operation without operationId", where))
+			} else if other, ok := operationIDs[op.OperationID]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s: operationId %q already used by %s", where, op.OperationID, other))
+			} else {
+				operationIDs[op.OperationID] = where
+			}
+			if len(op.Responses) == 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: operation without responses", where))
+			}
+		}
+	}
+	schemas := componentSchemas(swagger)
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref := schemas[name]
+		if ref == nil || ref.Ref != "" || ref.Value == nil {
+			continue
+		}
+		s := ref.Value
+		if s.Type == "" && len(s.AllOf) == 0 && len(s.OneOf) == 0 && len(s.AnyOf) == 0 && s.Not == nil {
+			warnings = append(warnings, fmt.Sprintf("schema %s: schema without type", name))
+		}
+	}
+	return warnings
+}
+
+// warnSpecs logs the warnings of every spec and returns how many were found.
+func warnSpecs(specs []loadedSpec) int {
+	var count int
+	for _, s := range specs {
+		for _, w := range specWarnings(s.swagger) {
+			logWarning(w, s.name)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 cirello.io and github.com/ucirello
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpecWarnings(t *testing.T) {
+	if got := specWarnings(parseTestSpec(t, petstoreSpec, false)); len(got) != 0 {
+		t.Errorf("specWarnings(petstore) = %v, want none", got)
+	}
+	swagger := parseTestSpec(t, strings.Replace(petstoreSpec, `"operationId": "listPets", `, "", 1), false)
+	want := []string{"GET /pets: operation without operationId"}
+	if got := specWarnings(swagger); !reflect.DeepEqual(got, want) {
+		t.Errorf("specWarnings() = %v, want %v", got, want)
+	}
+}
+
+func TestFailOnWarning(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"spec.json":         strings.Replace(petstoreSpec, `"operationId": "listPets", `, "", 1),
+		"tpl/title.txt.tpl": "{{ .Info.Title }}",
+	})
+	out, code := runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out")
+	if code != 0 {
+		t.Fatalf("warnings alone should not fail the run: %s", out)
+	}
+	if !strings.Contains(out, "operation without operationId") {
+		t.Errorf("missing operationId was not reported: %s", out)
+	}
+	out, code = runOpenapigen(t, dir, nil, "-spec", "spec.json", "-template", "tpl", "-output", "out", "-fail-on-warning")
+	if code == 0 {
+		t.Errorf("-fail-on-warning should fail on a missing operationId: %s", out)
+	}
+}